@@ -60,24 +60,31 @@ func (resp bansResponse) NextURL(r Recursive) (string, error) {
 }
 
 type BanOpts struct {
-	Recursive
-	PlayerID int    `url:"player,omitempty"` // etf2l player id only, no steamid
-	Status   string `url:"status,omitempty"` // 'active' or 'expired'
-	Reason   string `url:"reason,omitempty"` // 'VAC`
+	Recursive `url:"-"`
+	PlayerID  int    `url:"player,omitempty"` // etf2l player id only, no steamid
+	Status    string `url:"status,omitempty"` // 'active' or 'expired'
+	Reason    string `url:"reason,omitempty"` // 'VAC`
 }
 
 func (client *Client) Bans(ctx context.Context, httpClient HTTPExecutor, opts BanOpts) ([]Ban, error) {
-	curPath := "/bans"
+	curPath, errPath := queryPath("/bans", opts)
+	if errPath != nil {
+		return nil, errPath
+	}
+
 	max500s := 15
 	cur500s := 0
+	// retry counts the failed requests since the last successful one.
+	retry := 0
 
 	var bans []Ban
 
 	for {
 		var resp bansResponse
-		if err := client.call(ctx, httpClient, curPath, opts, &resp); err != nil {
+		if err := client.call(withRetry(ctx, retry), httpClient, curPath, nil, &resp); err != nil {
 			if strings.Contains(err.Error(), "500") {
 				cur500s++
+				retry++
 				if cur500s >= max500s {
 					slog.Info("Too many 500s")
 
@@ -97,6 +104,7 @@ func (client *Client) Bans(ctx context.Context, httpClient HTTPExecutor, opts Ba
 			return nil, err
 		}
 
+		retry = 0
 		bans = append(bans, resp.Pager.Data...)
 
 		nextURL, err := resp.NextURL(opts)
@@ -108,7 +116,10 @@ func (client *Client) Bans(ctx context.Context, httpClient HTTPExecutor, opts Ba
 			return nil, err
 		}
 
-		curPath = nextURL
+		curPath, errPath = pagePath(nextURL, opts)
+		if errPath != nil {
+			return nil, errPath
+		}
 	}
 
 	return bans, nil
@@ -0,0 +1,320 @@
+package etf2l
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type BracketSide string
+
+const (
+	BracketUpper      BracketSide = "upper"
+	BracketLower      BracketSide = "lower"
+	BracketGrandFinal BracketSide = "grand_final"
+)
+
+type BracketKind string
+
+const (
+	SingleElimination BracketKind = "single_elimination"
+	DoubleElimination BracketKind = "double_elimination"
+)
+
+// finalDistance maps round names to how many rounds they are away from the final of their side.
+var finalDistance = []struct {
+	keyword  string
+	distance int
+}{
+	{"round of 32", 4},
+	{"ro32", 4},
+	{"round of 16", 3},
+	{"ro16", 3},
+	{"eighth", 3},
+	{"quarter", 2},
+	{"semi", 1},
+	{"final", 0},
+}
+
+var roundNumber = regexp.MustCompile(`round\s*(\d+)`)
+
+var playoffKeywords = []string{"final", "semi", "quarter", "playoff", "bracket", "round of", "ro16", "ro32", "eighth"}
+
+// BracketRoundInfo is the position of a round within a bracket as parsed from its name.
+type BracketRoundInfo struct {
+	Side BracketSide
+	// Order sorts the rounds of a side, earliest first.
+	Order int
+}
+
+// ParseBracketRound parses a round name, eg: "Upper Bracket Semi Final" or "Lower Round 2", into its bracket
+// position. Rounds which do not look like playoff rounds, eg: "Week 3", are reported as not found.
+func ParseBracketRound(round string) (BracketRoundInfo, bool) {
+	name := strings.NewReplacer("-", " ", "_", " ").Replace(strings.ToLower(round))
+	if !slices.ContainsFunc(playoffKeywords, func(keyword string) bool { return strings.Contains(name, keyword) }) &&
+		!strings.Contains(name, "upper") && !strings.Contains(name, "lower") {
+		return BracketRoundInfo{}, false
+	}
+
+	info := BracketRoundInfo{Side: BracketUpper}
+
+	switch {
+	case strings.Contains(name, "grand final"):
+		info.Side = BracketGrandFinal
+	case strings.Contains(name, "lower"), strings.Contains(name, "loser"):
+		info.Side = BracketLower
+	}
+
+	if match := roundNumber.FindStringSubmatch(name); match != nil {
+		number, _ := strconv.Atoi(match[1])
+		info.Order = number
+
+		return info, true
+	}
+
+	for _, entry := range finalDistance {
+		if strings.Contains(name, entry.keyword) {
+			// Rounds named by their distance to the final are always played after numbered rounds.
+			info.Order = 1000 - entry.distance
+
+			return info, true
+		}
+	}
+
+	return info, true
+}
+
+type BracketTeam struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Country string `json:"country"`
+}
+
+// BracketMatch is a single playoff match. Advancement links reference other matches by their id and are zero
+// when the team did not play another match in the bracket.
+type BracketMatch struct {
+	ID         int         `json:"id"`
+	Round      string      `json:"round"`
+	Side       BracketSide `json:"side"`
+	Week       int         `json:"week"`
+	Clan1      BracketTeam `json:"clan1"`
+	Clan2      BracketTeam `json:"clan2"`
+	R1         int         `json:"r1"`
+	R2         int         `json:"r2"`
+	DefaultWin bool        `json:"default_win"`
+	Maps       []string    `json:"maps"`
+	Played     bool        `json:"played"`
+	// Winner is the id of the winning team, or 0 while unplayed or drawn.
+	Winner   int   `json:"winner"`
+	WinnerTo int   `json:"winner_to,omitempty"`
+	LoserTo  int   `json:"loser_to,omitempty"`
+	From     []int `json:"from,omitempty"`
+
+	order int
+}
+
+func (m BracketMatch) loser() int {
+	switch m.Winner {
+	case 0:
+		return 0
+	case m.Clan1.ID:
+		return m.Clan2.ID
+	default:
+		return m.Clan1.ID
+	}
+}
+
+func (m BracketMatch) hasTeam(teamID int) bool {
+	return teamID != 0 && (m.Clan1.ID == teamID || m.Clan2.ID == teamID)
+}
+
+type BracketRound struct {
+	Name    string         `json:"name"`
+	Side    BracketSide    `json:"side"`
+	Matches []BracketMatch `json:"matches"`
+}
+
+// Bracket is the playoff tree of a single division. Rounds are ordered from the first to the final.
+type Bracket struct {
+	DivisionID   int            `json:"division_id"`
+	DivisionName string         `json:"division_name"`
+	Tier         int            `json:"tier"`
+	Kind         BracketKind    `json:"kind"`
+	Upper        []BracketRound `json:"upper"`
+	Lower        []BracketRound `json:"lower,omitempty"`
+	GrandFinal   []BracketMatch `json:"grand_final,omitempty"`
+}
+
+// Match returns a match of the bracket by its id.
+func (b Bracket) Match(matchID int) (BracketMatch, bool) {
+	for _, rounds := range [][]BracketRound{b.Upper, b.Lower, {{Matches: b.GrandFinal}}} {
+		for _, round := range rounds {
+			if idx := slices.IndexFunc(round.Matches, func(match BracketMatch) bool { return match.ID == matchID }); idx >= 0 {
+				return round.Matches[idx], true
+			}
+		}
+	}
+
+	return BracketMatch{}, false
+}
+
+func newBracketMatch(match CompetitionMatch, info BracketRoundInfo) BracketMatch {
+	bracketMatch := BracketMatch{
+		ID:         match.ID,
+		Round:      match.Round,
+		Side:       info.Side,
+		Week:       match.Week,
+		Clan1:      BracketTeam{ID: match.Clan1.ID, Name: match.Clan1.Name, Country: match.Clan1.Country},
+		Clan2:      BracketTeam{ID: match.Clan2.ID, Name: match.Clan2.Name, Country: match.Clan2.Country},
+		R1:         match.Result.R1,
+		R2:         match.Result.R2,
+		DefaultWin: match.Defaultwin,
+		Maps:       match.Maps,
+		Played:     match.Defaultwin || match.Result.R1+match.Result.R2 > 0,
+		order:      info.Order,
+	}
+
+	switch outcome(match.Result.R1, match.Result.R2) {
+	case OutcomeWin:
+		bracketMatch.Winner = match.Clan1.ID
+	case OutcomeLoss:
+		bracketMatch.Winner = match.Clan2.ID
+	case OutcomeDraw:
+	}
+
+	return bracketMatch
+}
+
+var sideOrder = map[BracketSide]int{BracketUpper: 0, BracketLower: 1, BracketGrandFinal: 2}
+
+// compareBracketMatches orders matches in the sequence they are played, lower bracket rounds are played after the
+// upper bracket round of the same order.
+func compareBracketMatches(a, b BracketMatch) int {
+	switch {
+	case a.Week != b.Week:
+		return a.Week - b.Week
+	case a.Side == BracketGrandFinal || b.Side == BracketGrandFinal:
+		return sideOrder[a.Side] - sideOrder[b.Side]
+	case a.order != b.order:
+		return a.order - b.order
+	case a.Side != b.Side:
+		return sideOrder[a.Side] - sideOrder[b.Side]
+	default:
+		return a.ID - b.ID
+	}
+}
+
+// NewBrackets builds the playoff brackets of each division from the matches of a competition. Regular season
+// matches are ignored. Advancement links are inferred by following each team to the next match it played, so
+// they are only known once a match has been scheduled.
+func NewBrackets(matches []CompetitionMatch) []Bracket {
+	var (
+		brackets []Bracket
+		byDiv    = map[int]int{}
+		divMatch = map[int][]BracketMatch{}
+	)
+
+	for _, match := range matches {
+		info, isPlayoff := ParseBracketRound(match.Round)
+		if !isPlayoff {
+			continue
+		}
+
+		if _, found := byDiv[match.Division.ID]; !found {
+			byDiv[match.Division.ID] = len(brackets)
+			brackets = append(brackets, Bracket{
+				DivisionID:   match.Division.ID,
+				DivisionName: match.Division.Name,
+				Tier:         match.Division.Tier,
+				Kind:         SingleElimination,
+			})
+		}
+
+		divMatch[match.Division.ID] = append(divMatch[match.Division.ID], newBracketMatch(match, info))
+	}
+
+	for idx := range brackets {
+		brackets[idx].build(divMatch[brackets[idx].DivisionID])
+	}
+
+	slices.SortStableFunc(brackets, func(a, b Bracket) int {
+		return a.Tier - b.Tier
+	})
+
+	return brackets
+}
+
+func (b *Bracket) build(matches []BracketMatch) {
+	slices.SortStableFunc(matches, compareBracketMatches)
+
+	if slices.ContainsFunc(matches, func(match BracketMatch) bool { return match.Side != BracketUpper }) {
+		b.Kind = DoubleElimination
+	}
+
+	linkBracketMatches(matches)
+
+	rounds := map[BracketSide][]BracketRound{}
+
+	for _, match := range matches {
+		if match.Side == BracketGrandFinal {
+			b.GrandFinal = append(b.GrandFinal, match)
+
+			continue
+		}
+
+		sideRounds := rounds[match.Side]
+		idx := slices.IndexFunc(sideRounds, func(round BracketRound) bool { return round.Name == match.Round })
+
+		if idx < 0 {
+			idx = len(sideRounds)
+			sideRounds = append(sideRounds, BracketRound{Name: match.Round, Side: match.Side})
+		}
+
+		sideRounds[idx].Matches = append(sideRounds[idx].Matches, match)
+		rounds[match.Side] = sideRounds
+	}
+
+	b.Upper = rounds[BracketUpper]
+	b.Lower = rounds[BracketLower]
+}
+
+// linkBracketMatches sets the advancement links of matches which are ordered as played.
+func linkBracketMatches(matches []BracketMatch) {
+	next := func(from int, teamID int) int {
+		for idx := from + 1; idx < len(matches); idx++ {
+			if matches[idx].hasTeam(teamID) {
+				return idx
+			}
+		}
+
+		return -1
+	}
+
+	for idx := range matches {
+		if winnerIdx := next(idx, matches[idx].Winner); winnerIdx >= 0 {
+			matches[idx].WinnerTo = matches[winnerIdx].ID
+			matches[winnerIdx].From = append(matches[winnerIdx].From, matches[idx].ID)
+		}
+
+		if matches[idx].Side != BracketUpper {
+			continue
+		}
+
+		if loserIdx := next(idx, matches[idx].loser()); loserIdx >= 0 && matches[loserIdx].Side == BracketLower {
+			matches[idx].LoserTo = matches[loserIdx].ID
+			matches[loserIdx].From = append(matches[loserIdx].From, matches[idx].ID)
+		}
+	}
+}
+
+// CompetitionBrackets fetches the matches of a competition and builds the playoff bracket of each division.
+func (client *Client) CompetitionBrackets(ctx context.Context, httpClient HTTPExecutor, competitionID int) ([]Bracket, error) {
+	matches, errMatches := client.CompetitionMatches(ctx, httpClient, competitionID, BaseOpts{Recursive: true})
+	if errMatches != nil {
+		return nil, errMatches
+	}
+
+	return NewBrackets(matches), nil
+}
@@ -0,0 +1,229 @@
+package etf2l
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultMatchDuration is the length of calendar events, ETF2L does not provide an end time for matches.
+const DefaultMatchDuration = 2 * time.Hour
+
+const (
+	icsTimeFormat = "20060102T150405Z"
+	icsLineLimit  = 75
+)
+
+// Calendar renders scheduled matches as an RFC 5545 iCalendar. Event UIDs are derived from the match id, so a
+// calendar client re-importing an updated calendar replaces existing events instead of duplicating them. The api
+// does not say when a match was last rescheduled, so every event is versioned by the time the calendar was
+// generated, which makes each export a newer revision of the events already imported.
+type Calendar struct {
+	Name string
+	// TeamID sets the perspective of event summaries, eg: "vs Opponent" instead of "Team A vs Team B". Optional.
+	TeamID int
+	// Duration of each event, defaults to DefaultMatchDuration.
+	Duration time.Duration
+	// Generated is used as the DTSTAMP, LAST-MODIFIED and SEQUENCE of every event, defaults to the current time.
+	Generated time.Time
+	Matches   []Match
+}
+
+// NewCalendar creates a calendar of the provided matches. Matches without a scheduled time are skipped when
+// rendering.
+func NewCalendar(name string, teamID int, matches []Match) Calendar {
+	return Calendar{Name: name, TeamID: teamID, Matches: matches}
+}
+
+// calendarSequence converts the generation time into a SEQUENCE, counted in minutes to stay within the 32-bit
+// integers used by iCalendar.
+func calendarSequence(generated time.Time) int64 {
+	return max(generated.Unix()/60, 0)
+}
+
+// MatchUID returns the stable iCalendar UID of a match.
+func MatchUID(matchID int) string {
+	return fmt.Sprintf("match-%d@etf2l.org", matchID)
+}
+
+// WriteTo writes the calendar in the iCalendar format.
+func (c Calendar) WriteTo(writer io.Writer) (int64, error) {
+	ics := &icsWriter{writer: writer}
+
+	ics.line("BEGIN:VCALENDAR")
+	ics.line("VERSION:2.0")
+	ics.line("PRODID:-//leighmacdonald//etf2l//EN")
+	ics.line("CALSCALE:GREGORIAN")
+	ics.line("METHOD:PUBLISH")
+
+	if c.Name != "" {
+		ics.line("X-WR-CALNAME:" + escapeICS(c.Name))
+	}
+
+	duration := c.Duration
+	if duration <= 0 {
+		duration = DefaultMatchDuration
+	}
+
+	generated := c.Generated
+	if generated.IsZero() {
+		generated = time.Now()
+	}
+
+	for _, match := range c.Matches {
+		if match.Time == 0 {
+			continue
+		}
+
+		start := time.Unix(int64(match.Time), 0).UTC()
+
+		ics.line("BEGIN:VEVENT")
+		ics.line("UID:" + MatchUID(match.ID))
+		ics.line("DTSTAMP:" + generated.UTC().Format(icsTimeFormat))
+		ics.line("LAST-MODIFIED:" + generated.UTC().Format(icsTimeFormat))
+		ics.line("SEQUENCE:" + strconv.FormatInt(calendarSequence(generated), 10))
+		ics.line("DTSTART:" + start.Format(icsTimeFormat))
+		ics.line("DTEND:" + start.Add(duration).Format(icsTimeFormat))
+		ics.line("SUMMARY:" + escapeICS(c.summary(match)))
+		ics.line("DESCRIPTION:" + escapeICS(matchDescription(match)))
+		ics.line("CATEGORIES:" + escapeICS(match.Competition.Name))
+
+		if match.Urls.Self != "" {
+			ics.line("URL:" + match.Urls.Self)
+		}
+
+		ics.line("END:VEVENT")
+	}
+
+	ics.line("END:VCALENDAR")
+
+	return ics.written, ics.err
+}
+
+func (c Calendar) summary(match Match) string {
+	switch c.TeamID {
+	case 0:
+		return fmt.Sprintf("%s vs %s", match.Clan1.Name, match.Clan2.Name)
+	case match.Clan1.ID:
+		return "vs " + match.Clan2.Name
+	case match.Clan2.ID:
+		return "vs " + match.Clan1.Name
+	default:
+		return fmt.Sprintf("%s vs %s", match.Clan1.Name, match.Clan2.Name)
+	}
+}
+
+func matchDescription(match Match) string {
+	lines := []string{
+		"Competition: " + match.Competition.Name,
+		"Division: " + match.Division.Name,
+		"Round: " + match.Round,
+	}
+
+	if len(match.Maps) > 0 {
+		lines = append(lines, "Maps: "+strings.Join(match.Maps, ", "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// escapeICS escapes a TEXT value as defined in RFC 5545 section 3.3.11.
+func escapeICS(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+// icsWriter writes content lines terminated by CRLF, folding lines longer than 75 octets.
+type icsWriter struct {
+	writer  io.Writer
+	written int64
+	err     error
+}
+
+func (w *icsWriter) line(content string) {
+	var builder strings.Builder
+
+	limit := icsLineLimit
+
+	for len(content) > limit {
+		cut := limit
+		// Avoid splitting multi-byte characters.
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+
+		builder.WriteString(content[:cut])
+		builder.WriteString("\r\n ")
+		content = content[cut:]
+		// Continuation lines start with a space which counts towards the limit.
+		limit = icsLineLimit - 1
+	}
+
+	builder.WriteString(content)
+	builder.WriteString("\r\n")
+
+	w.write(builder.String())
+}
+
+func (w *icsWriter) write(value string) {
+	if w.err != nil {
+		return
+	}
+
+	written, err := io.WriteString(w.writer, value)
+	w.written += int64(written)
+	w.err = err
+}
+
+// TeamCalendar fetches the upcoming matches of a team as a calendar.
+func (client *Client) TeamCalendar(ctx context.Context, httpClient HTTPExecutor, teamID int) (Calendar, error) {
+	matches, _, errMatches := client.Matches(ctx, httpClient, MatchesOpts{
+		BaseOpts:  BaseOpts{Recursive: true},
+		Vs:        teamID,
+		Scheduled: 1,
+	})
+	if errMatches != nil {
+		return Calendar{}, errMatches
+	}
+
+	return NewCalendar(fmt.Sprintf("ETF2L matches of team %d", teamID), teamID, matches), nil
+}
+
+// PlayerCalendar fetches the upcoming matches of every team a player is currently rostered on as a calendar,
+// ordered by start time.
+func (client *Client) PlayerCalendar(ctx context.Context, httpClient HTTPExecutor, playerID int) (Calendar, error) {
+	player, errPlayer := client.Player(ctx, httpClient, strconv.Itoa(playerID))
+	if errPlayer != nil {
+		return Calendar{}, errPlayer
+	}
+
+	var matches []Match
+
+	for _, team := range player.Teams {
+		teamMatches, _, errMatches := client.Matches(ctx, httpClient, MatchesOpts{
+			BaseOpts:  BaseOpts{Recursive: true},
+			Vs:        team.ID,
+			Scheduled: 1,
+		})
+		if errMatches != nil {
+			return Calendar{}, errMatches
+		}
+
+		for _, match := range teamMatches {
+			// Matches between two of the player's teams are returned for both of them.
+			if !slices.ContainsFunc(matches, func(existing Match) bool { return existing.ID == match.ID }) {
+				matches = append(matches, match)
+			}
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b Match) int {
+		return a.Time - b.Time
+	})
+
+	return NewCalendar(fmt.Sprintf("ETF2L matches of %s", player.Name), 0, matches), nil
+}
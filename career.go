@@ -0,0 +1,294 @@
+package etf2l
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// CareerRecord is a win/loss/draw tally. Maps won and lost are taken from the match scores. DefaultWins counts
+// the matches decided by a default win, for either side, which are excluded from the map tallies.
+type CareerRecord struct {
+	Played      int
+	Wins        int
+	Losses      int
+	Draws       int
+	DefaultWins int
+	MapsWon     int
+	MapsLost    int
+}
+
+func (r *CareerRecord) add(outcome MatchOutcome, defaultWin bool, mapsWon int, mapsLost int) {
+	r.Played++
+
+	switch outcome {
+	case OutcomeWin:
+		r.Wins++
+	case OutcomeLoss:
+		r.Losses++
+	case OutcomeDraw:
+		r.Draws++
+	}
+
+	if defaultWin {
+		r.DefaultWins++
+
+		return
+	}
+
+	r.MapsWon += mapsWon
+	r.MapsLost += mapsLost
+}
+
+type MatchOutcome int
+
+const (
+	OutcomeDraw MatchOutcome = iota
+	OutcomeWin
+	OutcomeLoss
+)
+
+// outcome compares two scores from the perspective of the first.
+func outcome(score int, opponent int) MatchOutcome {
+	switch {
+	case score > opponent:
+		return OutcomeWin
+	case score < opponent:
+		return OutcomeLoss
+	default:
+		return OutcomeDraw
+	}
+}
+
+// CareerTeam is a continuous stint a player spent on a team. Left is zero while the player is still on the team.
+type CareerTeam struct {
+	TeamID int
+	Name   string
+	Type   string
+	Joined time.Time
+	Left   time.Time
+}
+
+// CareerCompetition summarises a players participation in a single competition.
+type CareerCompetition struct {
+	ID       int
+	Name     string
+	Category string
+	Type     string
+	Division Division
+	// TeamID is the team the player represented, or 0 if only merced.
+	TeamID int
+	Record CareerRecord
+}
+
+// CareerMap counts the matches a player has played on a map. Scores are only attributed to a map when it was
+// the sole map of the match.
+type CareerMap struct {
+	Played int
+	Won    int
+	Lost   int
+}
+
+// PlayerCareer is a summary of a players history built from their results and transfers.
+type PlayerCareer struct {
+	PlayerID     int
+	Teams        []CareerTeam
+	Competitions []CareerCompetition
+	// Divisions contains each division played in, ordered from the highest, lowest tier value, to the lowest.
+	Divisions []Division
+	Overall   CareerRecord
+	// ByType holds the records keyed by competition type, eg: 6on6, Highlander.
+	ByType          map[string]CareerRecord
+	Maps            map[string]CareerMap
+	MercAppearances int
+	FirstMatch      time.Time
+	LastMatch       time.Time
+}
+
+// HighestDivision returns the highest division the player has played in.
+func (c PlayerCareer) HighestDivision() (Division, bool) {
+	if len(c.Divisions) == 0 {
+		return Division{}, false
+	}
+
+	return c.Divisions[0], true
+}
+
+// playerSide returns the score and clan of the players team, falling back to the result field when the
+// player was not in either team, as can be the case when merced.
+func playerSide(result PlayerResult) (int, int, PlayerResultClan, MatchOutcome) {
+	switch {
+	case result.Clan1.WasInTeam:
+		return result.R1, result.R2, result.Clan1, outcome(result.R1, result.R2)
+	case result.Clan2.WasInTeam:
+		return result.R2, result.R1, result.Clan2, outcome(result.R2, result.R1)
+	case result.Result > 0:
+		return max(result.R1, result.R2), min(result.R1, result.R2), PlayerResultClan{}, OutcomeWin
+	case result.Result < 0:
+		return min(result.R1, result.R2), max(result.R1, result.R2), PlayerResultClan{}, OutcomeLoss
+	default:
+		return result.R1, result.R2, PlayerResultClan{}, OutcomeDraw
+	}
+}
+
+// NewPlayerCareer builds a career summary from the complete results and transfer history of a player.
+func NewPlayerCareer(playerID int, results []PlayerResult, transfers []PlayerTransfer) PlayerCareer {
+	career := PlayerCareer{
+		PlayerID: playerID,
+		Teams:    careerTeams(transfers),
+		ByType:   map[string]CareerRecord{},
+		Maps:     map[string]CareerMap{},
+	}
+
+	competitions := map[int]int{}
+
+	for _, result := range results {
+		score, opponent, clan, matchOutcome := playerSide(result)
+		playedAt := time.Unix(int64(result.Time), 0)
+
+		if career.FirstMatch.IsZero() || playedAt.Before(career.FirstMatch) {
+			career.FirstMatch = playedAt
+		}
+
+		if playedAt.After(career.LastMatch) {
+			career.LastMatch = playedAt
+		}
+
+		if result.Merced {
+			career.MercAppearances++
+		}
+
+		career.Overall.add(matchOutcome, result.Defaultwin, score, opponent)
+
+		typeRecord := career.ByType[result.Competition.Type]
+		typeRecord.add(matchOutcome, result.Defaultwin, score, opponent)
+		career.ByType[result.Competition.Type] = typeRecord
+
+		idx, found := competitions[result.Competition.ID]
+		if !found {
+			idx = len(career.Competitions)
+			competitions[result.Competition.ID] = idx
+			career.Competitions = append(career.Competitions, CareerCompetition{
+				ID:       result.Competition.ID,
+				Name:     result.Competition.Name,
+				Category: result.Competition.Category,
+				Type:     result.Competition.Type,
+				Division: result.Division,
+			})
+		}
+
+		if !result.Merced && clan.ID != 0 {
+			career.Competitions[idx].TeamID = clan.ID
+		}
+
+		career.Competitions[idx].Record.add(matchOutcome, result.Defaultwin, score, opponent)
+
+		if result.Division.Name != "" && !slices.ContainsFunc(career.Divisions, func(division Division) bool {
+			return division.Name == result.Division.Name
+		}) {
+			career.Divisions = append(career.Divisions, result.Division)
+		}
+
+		if !result.Defaultwin {
+			career.addMaps(result.Maps, matchOutcome)
+		}
+	}
+
+	slices.SortStableFunc(career.Divisions, func(a, b Division) int {
+		return a.Tier - b.Tier
+	})
+
+	return career
+}
+
+func (c *PlayerCareer) addMaps(maps []string, result MatchOutcome) {
+	for _, mapName := range maps {
+		record := c.Maps[mapName]
+		record.Played++
+
+		if len(maps) == 1 {
+			switch result {
+			case OutcomeWin:
+				record.Won++
+			case OutcomeLoss:
+				record.Lost++
+			case OutcomeDraw:
+			}
+		}
+
+		c.Maps[mapName] = record
+	}
+}
+
+// careerTeams turns the transfer history into a list of stints ordered by when they started.
+func careerTeams(transfers []PlayerTransfer) []CareerTeam {
+	sorted := slices.Clone(transfers)
+	slices.Reverse(sorted)
+	slices.SortStableFunc(sorted, func(a, b PlayerTransfer) int {
+		return a.Time - b.Time
+	})
+
+	var (
+		teams []CareerTeam
+		open  = map[int]int{}
+	)
+
+	for _, transfer := range sorted {
+		action, _ := ParseTransferType(transfer.Type)
+		idx, isOpen := open[transfer.Team.ID]
+		at := time.Unix(int64(transfer.Time), 0)
+
+		switch action {
+		case TransferJoin:
+			if isOpen {
+				continue
+			}
+
+			open[transfer.Team.ID] = len(teams)
+			teams = append(teams, CareerTeam{
+				TeamID: transfer.Team.ID,
+				Name:   transfer.Team.Name,
+				Type:   transfer.Team.Type,
+				Joined: at,
+			})
+		case TransferLeave:
+			if !isOpen {
+				// The join predates the available history.
+				teams = append(teams, CareerTeam{
+					TeamID: transfer.Team.ID,
+					Name:   transfer.Team.Name,
+					Type:   transfer.Team.Type,
+					Left:   at,
+				})
+
+				continue
+			}
+
+			teams[idx].Left = at
+			delete(open, transfer.Team.ID)
+		case TransferRoleChange, TransferUnknown:
+		}
+	}
+
+	slices.SortStableFunc(teams, func(a, b CareerTeam) int {
+		return a.Joined.Compare(b.Joined)
+	})
+
+	return teams
+}
+
+// PlayerCareer fetches the complete results and transfer history of a player and summarises them.
+func (client *Client) PlayerCareer(ctx context.Context, httpClient HTTPExecutor, playerID int) (PlayerCareer, error) {
+	results, errResults := client.PlayerResults(ctx, httpClient, strconv.Itoa(playerID), BaseOpts{Recursive: true})
+	if errResults != nil {
+		return PlayerCareer{}, errResults
+	}
+
+	transfers, errTransfers := client.PlayerTransfers(ctx, httpClient, playerID, BaseOpts{Recursive: true})
+	if errTransfers != nil {
+		return PlayerCareer{}, errTransfers
+	}
+
+	return NewPlayerCareer(playerID, results, transfers), nil
+}
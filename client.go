@@ -7,8 +7,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
 	"sync"
 
+	"github.com/google/go-querystring/query"
 	"github.com/pkg/errors"
 )
 
@@ -31,27 +35,116 @@ type Status struct {
 	Message string `json:"message"`
 }
 
-func fullURL(path string) string {
-	return fmt.Sprintf("https://api-v2.etf2l.org%s", path)
+// DefaultBaseURL is the address of the official ETF2L v2 api.
+const DefaultBaseURL = "https://api-v2.etf2l.org"
+
+func (client *Client) fullURL(path string) string {
+	return fmt.Sprintf("%s%s", client.baseURL, path)
 }
 
 type Client struct {
+	// RWMutex serialises the requests made against the api as a basic form of rate limiting.
 	sync.RWMutex
+	// inflight collapses identical concurrent requests so that only a single network call is made
+	// and its response body is shared with every waiting caller.
+	inflight   map[flightKey]*flight
+	inflightMu sync.Mutex
+	// middleware wraps every HTTPExecutor passed into the client, the first entry is the outermost.
+	middleware []Middleware
+	baseURL    string
+	strict     StrictMode
+	schema     schemaIssues
+}
+
+// Option configures optional Client behaviour.
+type Option func(client *Client)
+
+// WithMiddleware appends middleware to the chain that wraps each outgoing request.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(client *Client) {
+		client.middleware = append(client.middleware, middleware...)
+	}
 }
 
-func New() *Client {
-	return &Client{}
+// WithBaseURL overrides the api address, eg: to point the client at a mirror or an etf2ltest.Server.
+func WithBaseURL(baseURL string) Option {
+	return func(client *Client) {
+		client.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+func New(opts ...Option) *Client {
+	client := &Client{baseURL: DefaultBaseURL}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
 }
 
 type HTTPExecutor interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-func (client *Client) call(ctx context.Context, httpClient HTTPExecutor, path string, body any, receiver any) error {
-	client.Lock()
-	defer client.Unlock()
+// queryPath appends the filters of opts, encoded using their url tags, to the query string of path.
+func queryPath(path string, opts any) (string, error) {
+	filters, errQuery := query.Values(opts)
+	if errQuery != nil {
+		return "", errors.Wrap(errQuery, "Failed to encode query")
+	}
 
-	var reqBody io.Reader
+	if len(filters) == 0 {
+		return path, nil
+	}
+
+	return path + "?" + filters.Encode(), nil
+}
+
+// pagePath applies the filters of opts to the path of a page link. The api only carries the page number in its
+// links, following them as is would return every result from the second page onwards.
+func pagePath(path string, opts any) (string, error) {
+	parsed, errParse := url.Parse(path)
+	if errParse != nil {
+		return "", errors.Wrap(errParse, "Failed to parse URL")
+	}
+
+	filters, errQuery := query.Values(opts)
+	if errQuery != nil {
+		return "", errors.Wrap(errQuery, "Failed to encode query")
+	}
+
+	values := parsed.Query()
+	for key, value := range filters {
+		values[key] = value
+	}
+
+	return parsed.Path + "?" + values.Encode(), nil
+}
+
+// flightKey identifies requests which can share a response. Requests made with different executors are never
+// shared as the executors may differ in authentication, proxies or middleware.
+type flightKey struct {
+	executor HTTPExecutor
+	request  string
+}
+
+// flight is a request shared by every caller waiting on its response.
+type flight struct {
+	done    chan struct{}
+	body    []byte
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// call performs a GET request against the api and decodes the response into receiver. Identical requests (same
+// executor, path, query and body) that are in flight at the same time are deduplicated, a single request is made
+// and all callers decode the shared response body. Executors which are not comparable, eg: an HTTPExecutorFunc,
+// cannot be told apart so their requests are never shared. A shared request keeps running while any caller is
+// waiting on it and is cancelled once the context of every caller is done.
+func (client *Client) call(ctx context.Context, httpClient HTTPExecutor, path string, body any, receiver any) error {
+	var reqBody []byte
 
 	if body != nil {
 		rb, errMarshal := json.Marshal(body)
@@ -59,20 +152,118 @@ func (client *Client) call(ctx context.Context, httpClient HTTPExecutor, path st
 			return errors.Wrap(errMarshal, "Failed to marshal payload")
 		}
 
-		reqBody = bytes.NewReader(rb)
+		reqBody = rb
 	}
 
-	req, errReq := http.NewRequestWithContext(ctx, http.MethodGet, fullURL(path), reqBody)
+	var (
+		respBody []byte
+		errFetch error
+	)
+
+	if reflect.ValueOf(httpClient).Comparable() {
+		key := flightKey{executor: httpClient, request: http.MethodGet + " " + path + " " + string(reqBody)}
+		respBody, errFetch = client.share(ctx, key, httpClient, path, reqBody)
+	} else {
+		respBody, errFetch = client.fetch(ctx, httpClient, path, reqBody)
+	}
+
+	if errFetch != nil {
+		return errFetch
+	}
+
+	if errSchema := client.checkSchema(path, respBody, receiver); errSchema != nil {
+		return errSchema
+	}
+
+	if errJSON := json.Unmarshal(respBody, &receiver); errJSON != nil {
+		return errors.Wrap(errJSON, "Failed to unmarshal json payload")
+	}
+
+	return nil
+}
+
+// share joins the in-flight request for key, starting it when there is none, and waits for its response.
+func (client *Client) share(ctx context.Context, key flightKey, httpClient HTTPExecutor, path string, body []byte) ([]byte, error) {
+	client.inflightMu.Lock()
+
+	shared, found := client.inflight[key]
+	if !found {
+		// The request outlives the caller which started it, but keeps its values, eg: the tracing span.
+		fetchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		shared = &flight{done: make(chan struct{}), cancel: cancel}
+
+		if client.inflight == nil {
+			client.inflight = map[flightKey]*flight{}
+		}
+
+		client.inflight[key] = shared
+
+		go func() {
+			defer cancel()
+
+			shared.body, shared.err = client.fetch(fetchCtx, httpClient, path, body)
+
+			client.forget(key, shared)
+			close(shared.done)
+		}()
+	}
+
+	shared.waiters++
+	client.inflightMu.Unlock()
+
+	select {
+	case <-shared.done:
+		return shared.body, shared.err
+	case <-ctx.Done():
+		client.inflightMu.Lock()
+		defer client.inflightMu.Unlock()
+
+		shared.waiters--
+		if shared.waiters == 0 {
+			// Later callers must start a new request rather than join the cancelled one.
+			if client.inflight[key] == shared {
+				delete(client.inflight, key)
+			}
+
+			shared.cancel()
+		}
+
+		return nil, ctx.Err()
+	}
+}
+
+// forget removes a finished or abandoned request so that it is no longer joined.
+func (client *Client) forget(key flightKey, shared *flight) {
+	client.inflightMu.Lock()
+	defer client.inflightMu.Unlock()
+
+	if client.inflight[key] == shared {
+		delete(client.inflight, key)
+	}
+}
+
+// fetch executes the request and returns the raw response body.
+func (client *Client) fetch(ctx context.Context, httpClient HTTPExecutor, path string, body []byte) ([]byte, error) {
+	client.Lock()
+	defer client.Unlock()
+
+	var reqBody io.Reader
+
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodGet, client.fullURL(path), reqBody)
 	if errReq != nil {
-		return errors.Wrap(errReq, "Failed to create request")
+		return nil, errors.Wrap(errReq, "Failed to create request")
 	}
 
 	req.Header.Add("Content-Type", `application/json`)
 	req.Header.Add("Accept", "application/json")
 
-	resp, errResp := httpClient.Do(req)
+	resp, errResp := client.chain(httpClient).Do(req)
 	if errResp != nil {
-		return errors.Wrap(errResp, "Failed to call endpoint")
+		return nil, errors.Wrap(errResp, "Failed to call endpoint")
 	}
 
 	defer func() {
@@ -80,21 +271,21 @@ func (client *Client) call(ctx context.Context, httpClient HTTPExecutor, path st
 	}()
 
 	if resp.StatusCode == http.StatusTooManyRequests {
-		return errors.New("Rate limited")
+		return nil, errors.New("Rate limited")
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode > http.StatusIMUsed {
 		if resp.StatusCode == http.StatusNotFound {
-			return ErrNotFound
+			return nil, ErrNotFound
 		}
 
-		return errors.Errorf("Invalid status code: %s", resp.Status)
+		return nil, errors.Errorf("Invalid status code: %s", resp.Status)
 	}
 
-	decoder := json.NewDecoder(resp.Body)
-	if errJSON := decoder.Decode(&receiver); errJSON != nil {
-		return errors.Wrap(errJSON, "Failed to unmarshal json payload")
+	respBody, errRead := io.ReadAll(resp.Body)
+	if errRead != nil {
+		return nil, errors.Wrap(errRead, "Failed to read response body")
 	}
 
-	return nil
+	return respBody, nil
 }
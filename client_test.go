@@ -1,26 +1,86 @@
 package etf2l_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/leighmacdonald/etf2l"
+	"github.com/leighmacdonald/etf2l/etf2ltest"
 	"github.com/leighmacdonald/steamid/v4/steamid"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 const (
 	testETF2LBannedID int = 139491
 )
 
+var record = flag.Bool("record", false, "Run against the live api and record the responses as fixtures")
+
+const (
+	// fixturesDir holds the responses recorded from the live api with -record.
+	fixturesDir = "testdata/fixtures"
+	// syntheticDir holds hand made responses in the recorder format, used for requests which have not been
+	// recorded. They follow the shape of the api but none of their contents are real.
+	syntheticDir = "testdata/synthetic"
+)
+
+// testExecutor serves the recorded fixtures, falling back to the synthetic ones, by default. When run with -record
+// the live api is used instead and the recorded fixtures are replaced with the fresh responses.
+var testExecutor etf2l.HTTPExecutor = etf2l.HTTPExecutorFunc(func(req *http.Request) (*http.Response, error) {
+	resp, err := etf2ltest.NewReplayer(fixturesDir).Do(req)
+	if errors.Is(err, etf2ltest.ErrFixtureNotFound) {
+		return etf2ltest.NewReplayer(syntheticDir).Do(req)
+	}
+
+	return resp, err
+})
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	if *record {
+		testExecutor = etf2ltest.NewRecorder(fixturesDir, &http.Client{})
+	}
+
+	os.Exit(m.Run())
+}
+
 var (
 	testIDb4nny  = steamid.New("76561197970669109")
 	testIDBanned = steamid.New("76561198203516436")
 )
 
 func TestClient(t *testing.T) {
-	client := etf2l.New()
+	// The synthetic fixtures are generated from the structs they are decoded into, schema drift can only be
+	// detected against the live api.
+	strict := etf2l.StrictOff
+	if *record {
+		strict = etf2l.StrictFail
+	}
+
+	client := etf2l.New(etf2l.WithStrictDecoding(strict))
 
 	t.Run("player", testPlayer(client))
 	t.Run("player_results", testPlayerResults(client))
@@ -47,18 +107,18 @@ func TestClient(t *testing.T) {
 
 func testPlayer(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		p1, err := client.Player(context.Background(), &http.Client{}, testIDb4nny.String())
+		p1, err := client.Player(context.Background(), testExecutor, testIDb4nny.String())
 		require.NoError(t, err)
 		require.Equal(t, 20834, p1.ID)
 
-		_, err404 := client.Player(context.Background(), &http.Client{}, "7999198203516436")
+		_, err404 := client.Player(context.Background(), testExecutor, "7999198203516436")
 		require.ErrorIs(t, etf2l.ErrNotFound, err404)
 	}
 }
 
 func testPlayerResults(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		results, err := client.PlayerResults(context.Background(), &http.Client{}, testIDBanned.String(), etf2l.BaseOpts{Recursive: false})
+		results, err := client.PlayerResults(context.Background(), testExecutor, testIDBanned.String(), etf2l.BaseOpts{Recursive: false})
 		require.NoError(t, err)
 		require.Equal(t, 20, len(results))
 	}
@@ -66,7 +126,7 @@ func testPlayerResults(client *etf2l.Client) func(*testing.T) {
 
 func testPlayerTransfers(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		results, err := client.PlayerTransfers(context.Background(), &http.Client{}, testETF2LBannedID, etf2l.BaseOpts{Recursive: false})
+		results, err := client.PlayerTransfers(context.Background(), testExecutor, testETF2LBannedID, etf2l.BaseOpts{Recursive: false})
 		require.NoError(t, err)
 		require.Equal(t, 20, len(results))
 	}
@@ -74,7 +134,7 @@ func testPlayerTransfers(client *etf2l.Client) func(*testing.T) {
 
 func testDemos(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		demos, err := client.Demos(context.Background(), &http.Client{}, etf2l.DemoOpts{
+		demos, err := client.Demos(context.Background(), testExecutor, etf2l.DemoOpts{
 			Recursive: etf2l.BaseOpts{Recursive: false},
 			PlayerID:  "2788",
 		})
@@ -85,28 +145,34 @@ func testDemos(client *etf2l.Client) func(*testing.T) {
 
 func testBans(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		bans, err := client.Bans(context.Background(), &http.Client{}, etf2l.BanOpts{
+		bans, err := client.Bans(context.Background(), testExecutor, etf2l.BanOpts{
 			Recursive: etf2l.BaseOpts{Recursive: false},
 			PlayerID:  testETF2LBannedID,
 		})
 		require.NoError(t, err)
-		require.True(t, len(bans) > 2)
+		require.Equal(t, 4, len(bans))
 	}
 }
 
 func testBansRecursive(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		bans, err := client.Bans(context.Background(), &http.Client{}, etf2l.BanOpts{
+		firstPage, errFirst := client.Bans(context.Background(), testExecutor, etf2l.BanOpts{
+			Recursive: etf2l.BaseOpts{Recursive: false},
+		})
+		require.NoError(t, errFirst)
+
+		bans, err := client.Bans(context.Background(), testExecutor, etf2l.BanOpts{
 			Recursive: etf2l.BaseOpts{Recursive: true},
 		})
 		require.NoError(t, err)
-		require.True(t, len(bans) > 3000)
+		require.Equal(t, 50, len(bans))
+		require.Equal(t, firstPage, bans[:len(firstPage)])
 	}
 }
 
 func testCompetitionList(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		competitions, err := client.CompetitionList(context.Background(), &http.Client{}, etf2l.CompetitionOpts{
+		competitions, err := client.CompetitionList(context.Background(), testExecutor, etf2l.CompetitionOpts{
 			Recursive: etf2l.BaseOpts{Recursive: false},
 		})
 		require.NoError(t, err)
@@ -116,7 +182,7 @@ func testCompetitionList(client *etf2l.Client) func(*testing.T) {
 
 func testCompetitionDetails(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		competition, err := client.CompetitionDetails(context.Background(), &http.Client{}, 1)
+		competition, err := client.CompetitionDetails(context.Background(), testExecutor, 1)
 		require.NoError(t, err)
 		require.Equal(t, 1, competition.ID)
 	}
@@ -124,56 +190,56 @@ func testCompetitionDetails(client *etf2l.Client) func(*testing.T) {
 
 func testCompetitionTeams(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		teams, err := client.CompetitionTeams(context.Background(), &http.Client{}, 1, etf2l.BaseOpts{
+		teams, err := client.CompetitionTeams(context.Background(), testExecutor, 1, etf2l.BaseOpts{
 			Recursive: false,
 		})
 		require.NoError(t, err)
-		require.True(t, len(teams) == 20)
+		require.Equal(t, 20, len(teams))
 	}
 }
 
 func testCompetitionResults(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		teams, err := client.CompetitionResults(context.Background(), &http.Client{}, 1, etf2l.BaseOpts{
+		teams, err := client.CompetitionResults(context.Background(), testExecutor, 1, etf2l.BaseOpts{
 			Recursive: false,
 		})
 		require.NoError(t, err)
-		require.True(t, len(teams) == 20)
+		require.Equal(t, 20, len(teams))
 	}
 }
 
 func testCompetitionMatches(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		teams, err := client.CompetitionMatches(context.Background(), &http.Client{}, 1, etf2l.BaseOpts{
+		teams, err := client.CompetitionMatches(context.Background(), testExecutor, 1, etf2l.BaseOpts{
 			Recursive: false,
 		})
 		require.NoError(t, err)
-		require.True(t, len(teams) == 20)
+		require.Equal(t, 20, len(teams))
 	}
 }
 
 func testCompetitionTables(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		teams, err := client.CompetitionResults(context.Background(), &http.Client{}, 1, etf2l.BaseOpts{
+		teams, err := client.CompetitionResults(context.Background(), testExecutor, 1, etf2l.BaseOpts{
 			Recursive: false,
 		})
 		require.NoError(t, err)
-		require.True(t, len(teams) > 5)
+		require.Equal(t, 20, len(teams))
 	}
 }
 
 func testMatches(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		pagesData, err := client.MatchesPage(context.Background(), &http.Client{}, 0, 2000)
+		pagesData, err := client.MatchesPage(context.Background(), testExecutor, 1, 2000)
 		require.NoError(t, err)
-		require.True(t, len(pagesData.Pager.Data) > 5)
-		require.True(t, pagesData.Pager.Total > 5)
+		require.Equal(t, 40, len(pagesData.Pager.Data))
+		require.Equal(t, 40, pagesData.Pager.Total)
 	}
 }
 
 func testMatchDetails(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		match, err := client.MatchDetails(context.Background(), &http.Client{}, 1)
+		match, err := client.MatchDetails(context.Background(), testExecutor, 1)
 		require.NoError(t, err)
 		require.Equal(t, 1, match.ID)
 	}
@@ -181,43 +247,45 @@ func testMatchDetails(client *etf2l.Client) func(*testing.T) {
 
 func testWhitelists(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		whitelists, err := client.Whitelists(context.Background(), &http.Client{})
+		whitelists, err := client.Whitelists(context.Background(), testExecutor)
 		require.NoError(t, err)
-		require.True(t, len(whitelists) > 4)
+		require.Equal(t, 6, len(whitelists))
 	}
 }
 
 func testPlayerRecruitment(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		recruitments, err := client.PlayerRecruitment(context.Background(), &http.Client{}, etf2l.RecruitmentOpts{
+		recruitments, err := client.PlayerRecruitment(context.Background(), testExecutor, etf2l.RecruitmentOpts{
 			BaseOpts: etf2l.BaseOpts{Recursive: false},
 		})
 		require.NoError(t, err)
 		require.Equal(t, 20, len(recruitments))
+		require.Equal(t, etf2l.PlayerClasses{"Medic", "Soldier"}, recruitments[1].Classes)
 	}
 }
 
 func testTeamRecruitment(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		recruitments, err := client.TeamRecruitment(context.Background(), &http.Client{}, etf2l.RecruitmentOpts{
+		recruitments, err := client.TeamRecruitment(context.Background(), testExecutor, etf2l.RecruitmentOpts{
 			BaseOpts: etf2l.BaseOpts{Recursive: false},
 		})
 		require.NoError(t, err)
 		require.Equal(t, 20, len(recruitments))
+		require.Equal(t, etf2l.PlayerClasses{"Heavy", "Scout"}, recruitments[1].Classes)
 	}
 }
 
 func testTeam(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		recruitments, err := client.Team(context.Background(), &http.Client{}, 2)
+		recruitments, err := client.Team(context.Background(), testExecutor, 2)
 		require.NoError(t, err)
-		require.Greater(t, len(recruitments.Competitions), 10)
+		require.Equal(t, 14, len(recruitments.Competitions))
 	}
 }
 
 func testTeamTransfers(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		transfers, err := client.TeamTransfers(context.Background(), &http.Client{}, 2, etf2l.BaseOpts{Recursive: false})
+		transfers, err := client.TeamTransfers(context.Background(), testExecutor, 2, etf2l.BaseOpts{Recursive: false})
 		require.NoError(t, err)
 		require.Equal(t, 20, len(transfers))
 	}
@@ -225,7 +293,7 @@ func testTeamTransfers(client *etf2l.Client) func(*testing.T) {
 
 func testTeamResults(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		results, err := client.TeamResults(context.Background(), &http.Client{}, 2, etf2l.BaseOpts{Recursive: false})
+		results, err := client.TeamResults(context.Background(), testExecutor, 2, etf2l.BaseOpts{Recursive: false})
 		require.NoError(t, err)
 		require.Equal(t, 20, len(results))
 	}
@@ -233,8 +301,1471 @@ func testTeamResults(client *etf2l.Client) func(*testing.T) {
 
 func testTeamMatches(client *etf2l.Client) func(*testing.T) {
 	return func(t *testing.T) {
-		results, err := client.TeamMatches(context.Background(), &http.Client{}, 2, etf2l.BaseOpts{Recursive: false})
+		results, err := client.TeamMatches(context.Background(), testExecutor, 2, etf2l.BaseOpts{Recursive: false})
 		require.NoError(t, err)
 		require.Equal(t, 20, len(results))
 	}
 }
+
+type countingExecutor struct {
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (e *countingExecutor) Do(req *http.Request) (*http.Response, error) {
+	if e.calls.Add(1) == 1 && e.started != nil {
+		close(e.started)
+	}
+
+	<-e.release
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     http.StatusText(http.StatusOK),
+		Body:       io.NopCloser(strings.NewReader(`{"team":{"id":2,"name":"test"},"status":{"code":200}}`)),
+		Request:    req,
+	}, nil
+}
+
+// waitingContext reports on arrived once the client starts waiting on it, which only happens after the caller has
+// joined the in-flight request.
+type waitingContext struct {
+	context.Context
+	once    sync.Once
+	arrived chan<- struct{}
+}
+
+func (c *waitingContext) Done() <-chan struct{} {
+	c.once.Do(func() {
+		c.arrived <- struct{}{}
+	})
+
+	return c.Context.Done()
+}
+
+func TestDeduplicateInflight(t *testing.T) {
+	client := etf2l.New()
+	executor := &countingExecutor{release: make(chan struct{})}
+
+	const callers = 10
+
+	var (
+		waitGroup sync.WaitGroup
+		arrived   = make(chan struct{}, callers)
+		teams     = make([]*etf2l.Team, callers)
+		errs      = make([]error, callers)
+	)
+
+	for idx := range callers {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			teams[idx], errs[idx] = client.Team(&waitingContext{Context: context.Background(), arrived: arrived}, executor, 2)
+		}()
+	}
+
+	// Only let the request complete once every caller has joined it.
+	for range callers {
+		<-arrived
+	}
+
+	close(executor.release)
+	waitGroup.Wait()
+
+	require.Equal(t, int32(1), executor.calls.Load())
+
+	for idx := range callers {
+		require.NoError(t, errs[idx])
+		require.Equal(t, 2, teams[idx].ID)
+	}
+
+	teams[0].Name = "changed"
+	require.Equal(t, "test", teams[1].Name)
+}
+
+func TestDeduplicateInflightCancel(t *testing.T) {
+	client := etf2l.New()
+	executor := &countingExecutor{started: make(chan struct{}), release: make(chan struct{})}
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+
+	go func() {
+		_, err := client.Team(leaderCtx, executor, 2)
+		leaderErr <- err
+	}()
+
+	<-executor.started
+
+	var (
+		arrived = make(chan struct{}, 1)
+		team    *etf2l.Team
+		errTeam error
+		done    = make(chan struct{})
+	)
+
+	go func() {
+		defer close(done)
+
+		team, errTeam = client.Team(&waitingContext{Context: context.Background(), arrived: arrived}, executor, 2)
+	}()
+
+	<-arrived
+
+	// The caller which started the request giving up must not fail the callers still waiting on it.
+	cancel()
+	require.ErrorIs(t, <-leaderErr, context.Canceled)
+
+	close(executor.release)
+	<-done
+
+	require.NoError(t, errTeam)
+	require.Equal(t, 2, team.ID)
+	require.Equal(t, int32(1), executor.calls.Load())
+}
+
+// blockingExecutor blocks every request until it is cancelled.
+type blockingExecutor struct {
+	started   chan struct{}
+	cancelled chan struct{}
+}
+
+func (e *blockingExecutor) Do(req *http.Request) (*http.Response, error) {
+	close(e.started)
+	<-req.Context().Done()
+	close(e.cancelled)
+
+	return nil, req.Context().Err()
+}
+
+func TestDeduplicateInflightAbandoned(t *testing.T) {
+	client := etf2l.New()
+	executor := &blockingExecutor{started: make(chan struct{}), cancelled: make(chan struct{})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errTeam := make(chan error, 1)
+
+	go func() {
+		_, err := client.Team(ctx, executor, 2)
+		errTeam <- err
+	}()
+
+	<-executor.started
+
+	// The request is cancelled once no caller is waiting on it any more.
+	cancel()
+	require.ErrorIs(t, <-errTeam, context.Canceled)
+
+	select {
+	case <-executor.cancelled:
+	case <-time.After(10 * time.Second):
+		t.Fatal("abandoned request was not cancelled")
+	}
+}
+
+func TestDeduplicateInflightExecutors(t *testing.T) {
+	client := etf2l.New()
+	first := &countingExecutor{started: make(chan struct{}), release: make(chan struct{})}
+	second := &countingExecutor{release: make(chan struct{})}
+
+	var (
+		waitGroup sync.WaitGroup
+		arrived   = make(chan struct{}, 1)
+		errs      = make([]error, 2)
+	)
+
+	waitGroup.Add(2)
+
+	go func() {
+		defer waitGroup.Done()
+
+		_, errs[0] = client.Team(context.Background(), first, 2)
+	}()
+
+	<-first.started
+
+	go func() {
+		defer waitGroup.Done()
+
+		_, errs[1] = client.Team(&waitingContext{Context: context.Background(), arrived: arrived}, second, 2)
+	}()
+
+	<-arrived
+
+	close(first.release)
+	close(second.release)
+	waitGroup.Wait()
+
+	require.NoError(t, errors.Join(errs...))
+
+	// The identical request made with another executor is not shared.
+	require.Equal(t, int32(1), first.calls.Load())
+	require.Equal(t, int32(1), second.calls.Load())
+}
+
+func TestMiddleware(t *testing.T) {
+	var (
+		order   []string
+		logs    bytes.Buffer
+		metrics = etf2l.NewMetrics("etf2l")
+	)
+
+	tag := func(name string) etf2l.Middleware {
+		return func(next etf2l.HTTPExecutor) etf2l.HTTPExecutor {
+			return etf2l.HTTPExecutorFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+
+				return next.Do(req)
+			})
+		}
+	}
+
+	client := etf2l.New(etf2l.WithMiddleware(
+		tag("first"),
+		tag("second"),
+		etf2l.LoggingMiddleware(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		metrics.Middleware(),
+	))
+	executor := &countingExecutor{release: make(chan struct{})}
+	close(executor.release)
+
+	_, err := client.Team(context.Background(), executor, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"first", "second"}, order)
+	require.Contains(t, logs.String(), "endpoint=/team/{id}")
+
+	var out bytes.Buffer
+	require.NoError(t, metrics.WritePrometheus(&out))
+	require.Contains(t, out.String(), `etf2l_requests_total{endpoint="/team/{id}",code="200"} 1`)
+	require.Contains(t, out.String(), `etf2l_request_duration_seconds_count{endpoint="/team/{id}"} 1`)
+}
+
+// recordingTracer keeps the attributes set on the spans it starts.
+type recordingTracer struct {
+	noop.Tracer
+	attributes []attribute.KeyValue
+}
+
+type recordingSpan struct {
+	noop.Span
+	tracer *recordingTracer
+}
+
+func (t *recordingTracer) Start(ctx context.Context, _ string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(opts...)
+	t.attributes = append(t.attributes, config.Attributes()...)
+
+	return ctx, recordingSpan{tracer: t}
+}
+
+func (s recordingSpan) SetAttributes(attributes ...attribute.KeyValue) {
+	s.tracer.attributes = append(s.tracer.attributes, attributes...)
+}
+
+func TestMiddlewareRetries(t *testing.T) {
+	server := etf2ltest.NewServer(etf2ltest.Fixtures{
+		Bans: []etf2l.Ban{
+			{Name: "one", Steamid64: steamid.New("76561197970669109")},
+			{Name: "two", Steamid64: steamid.New("76561197970669109")},
+			{Name: "three", Steamid64: steamid.New("76561197970669109")},
+		},
+	}, etf2ltest.WithPerPage(1))
+	defer server.Close()
+
+	var (
+		logs    bytes.Buffer
+		metrics = etf2l.NewMetrics("etf2l")
+		tracer  = &recordingTracer{}
+		failed  bool
+	)
+
+	// The second page fails once, Bans skips it and the request for the third page is a retry.
+	executor := etf2l.HTTPExecutorFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Query().Get("page") == "2" && !failed {
+			failed = true
+
+			return &http.Response{StatusCode: http.StatusInternalServerError, Status: "500 Internal Server Error", Body: http.NoBody, Request: req}, nil
+		}
+
+		return server.Client().Do(req)
+	})
+
+	client := server.NewClient(etf2l.WithMiddleware(
+		etf2l.LoggingMiddleware(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		etf2l.TracingMiddleware(tracer),
+		metrics.Middleware(),
+	))
+
+	bans, err := client.Bans(context.Background(), executor, etf2l.BanOpts{Recursive: etf2l.BaseOpts{Recursive: true}})
+	require.NoError(t, err)
+	require.Len(t, bans, 2)
+
+	require.Equal(t, 2, strings.Count(logs.String(), "retry=0"))
+	require.Equal(t, 1, strings.Count(logs.String(), "retry=1"))
+	require.Contains(t, tracer.attributes, attribute.Int("http.request.resend_count", 1))
+
+	var out bytes.Buffer
+	require.NoError(t, metrics.WritePrometheus(&out))
+	require.Contains(t, out.String(), `etf2l_requests_total{endpoint="/bans",code="200"} 2`)
+	require.Contains(t, out.String(), `etf2l_retries_total{endpoint="/bans"} 1`)
+}
+
+func TestStrictDecoding(t *testing.T) {
+	executor := etf2l.HTTPExecutorFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     http.StatusText(http.StatusOK),
+			Body: io.NopCloser(strings.NewReader(`{"team":{"id":2,"name":"test","founded":2010,` +
+				`"players":[{"id":"1","role":"Leader"},{"id":"2","role":"Player"}]},"status":{"code":200}}`)),
+			Request: req,
+		}, nil
+	})
+
+	lenient := etf2l.New()
+	_, errLenient := lenient.Team(context.Background(), executor, 2)
+	require.ErrorContains(t, errLenient, "Failed to unmarshal json payload")
+	require.Empty(t, lenient.SchemaIssues())
+
+	expected := []etf2l.SchemaIssue{
+		{Endpoint: "/team/{id}", Field: "team.founded", Kind: etf2l.UnknownField, Detail: "got number"},
+		{Endpoint: "/team/{id}", Field: "team.players[].id", Kind: etf2l.TypeMismatch, Detail: "expected integer, got string"},
+	}
+
+	strict := etf2l.New(etf2l.WithStrictDecoding(etf2l.StrictFail))
+	_, errStrict := strict.Team(context.Background(), executor, 2)
+	require.ErrorIs(t, errStrict, etf2l.ErrSchemaDrift)
+
+	var schemaErr *etf2l.SchemaError
+	require.ErrorAs(t, errStrict, &schemaErr)
+	require.Equal(t, expected, schemaErr.Issues)
+	require.Equal(t, map[string][]etf2l.SchemaIssue{"/team/{id}": expected}, strict.SchemaIssues())
+}
+
+func newTransfer(playerID int, transferType string, unixTime int) etf2l.TeamTransfer {
+	transfer := etf2l.TeamTransfer{Who: etf2l.TransferPlayerInfo{ID: playerID}, Type: transferType, Time: unixTime}
+	transfer.Team.ID = 2
+
+	return transfer
+}
+
+func TestRosterHistory(t *testing.T) {
+	// Newest first, as returned by the api.
+	transfers := []etf2l.TeamTransfer{
+		newTransfer(3, "joined", 400),
+		newTransfer(1, "left", 300),
+		newTransfer(2, "role_change", 200),
+		newTransfer(4, "left", 150),
+		newTransfer(1, "joined", 100),
+		newTransfer(2, "joined", 100),
+		newTransfer(4, "joined", 150),
+	}
+
+	oldestFirst := slices.Clone(transfers)
+	slices.Reverse(oldestFirst)
+
+	shuffled := []etf2l.TeamTransfer{transfers[3], transfers[0], transfers[6], transfers[2], transfers[5], transfers[1], transfers[4]}
+
+	for _, input := range [][]etf2l.TeamTransfer{transfers, oldestFirst, shuffled} {
+		history := etf2l.NewRosterHistory(2, input)
+
+		ids := func(at int64) map[int]string {
+			roster := map[int]string{}
+			for _, member := range history.At(time.Unix(at, 0)) {
+				roster[member.Player.ID] = member.Role
+			}
+
+			return roster
+		}
+
+		require.Empty(t, ids(99))
+		require.Equal(t, map[int]string{1: etf2l.RolePlayer, 2: etf2l.RolePlayer}, ids(100))
+		require.Equal(t, map[int]string{1: etf2l.RolePlayer, 2: etf2l.RolePlayer}, ids(150))
+		require.Equal(t, map[int]string{1: etf2l.RolePlayer, 2: etf2l.RoleUnknown}, ids(250))
+		require.Equal(t, map[int]string{2: etf2l.RoleUnknown}, ids(300))
+		require.Equal(t, map[int]string{2: etf2l.RoleUnknown, 3: etf2l.RolePlayer}, ids(500))
+		require.True(t, history.Contains(1, time.Unix(299, 0)))
+		require.False(t, history.Contains(1, time.Unix(300, 0)))
+	}
+}
+
+func TestCheckMatchEligibility(t *testing.T) {
+	player := func(id int, team int) etf2l.MatchPlayer {
+		matchPlayer := etf2l.MatchPlayer{ID: id, Team: team}
+		matchPlayer.Steam.ID64 = steamid.New(int64(76561197960265728 + id))
+
+		return matchPlayer
+	}
+
+	transfers := func(teamID int, transfers ...etf2l.TeamTransfer) []etf2l.TeamTransfer {
+		for idx := range transfers {
+			transfers[idx].Team.ID = teamID
+			transfers[idx].Who.Steam.ID64 = steamid.New(int64(76561197960265728 + transfers[idx].Who.ID))
+			transfers[idx].By.Steam.ID64 = transfers[idx].Who.Steam.ID64
+		}
+
+		return transfers
+	}
+
+	match := etf2l.MatchDetails{
+		ID:      10,
+		Clan1:   etf2l.MatchClan{ID: 1},
+		Clan2:   etf2l.MatchClan{ID: 2},
+		Time:    1000,
+		Players: []etf2l.MatchPlayer{player(1, 1), player(2, 1), player(3, 1), player(4, 2), player(5, 2)},
+	}
+
+	server := etf2ltest.NewServer(etf2ltest.Fixtures{
+		Matches:      []etf2l.Match{{ID: 10}},
+		MatchDetails: map[int]etf2l.MatchDetails{10: match},
+		TeamTransfers: map[int][]etf2l.TeamTransfer{
+			1: transfers(1, newTransfer(3, "joined", 1500), newTransfer(2, "joined", 500), newTransfer(1, "joined", 500)),
+			2: transfers(2, newTransfer(5, "joined", 500), newTransfer(4, "joined", 500)),
+		},
+	})
+	defer server.Close()
+
+	ban := etf2l.Ban{Start: 900, End: 2000, Steamid64: player(4, 2).Steam.ID64}
+	report, err := server.NewClient().CheckMatchEligibility(context.Background(), server.Client(), 10, []etf2l.Ban{ban})
+	require.NoError(t, err)
+	require.False(t, report.Eligible())
+	require.Len(t, report.Issues, 2)
+
+	require.Equal(t, etf2l.LateJoin, report.Issues[0].Kind)
+	require.Equal(t, 3, report.Issues[0].Player.ID)
+	require.Equal(t, time.Unix(1500, 0), report.Issues[0].JoinedAt)
+
+	require.Equal(t, etf2l.Banned, report.Issues[1].Kind)
+	require.Equal(t, 4, report.Issues[1].Player.ID)
+	require.Equal(t, ban.End, report.Issues[1].Ban.End)
+
+	// A missing roster history for one team still checks the player against the other team.
+	merc := etf2l.MatchDetails{ID: 11, Clan1: match.Clan1, Clan2: match.Clan2, Time: 1000, Players: []etf2l.MatchPlayer{player(6, 0)}}
+	rosterTwo := etf2l.NewRosterHistory(2, transfers(2, newTransfer(4, "joined", 500)))
+	partial := etf2l.NewEligibilityChecker([]etf2l.RosterHistory{rosterTwo}, nil).CheckMatch(merc)
+	require.Len(t, partial.Issues, 1)
+	require.Equal(t, etf2l.NotRostered, partial.Issues[0].Kind)
+
+	require.True(t, etf2l.NewEligibilityChecker(nil, nil).CheckMatch(merc).Eligible())
+}
+
+func TestPlayerCareer(t *testing.T) {
+	result := func(unixTime int, r1 int, r2 int, inClan1 bool, compType string, tier int, maps ...string) etf2l.PlayerResult {
+		return etf2l.PlayerResult{
+			Clan1:       etf2l.PlayerResultClan{ID: 1, WasInTeam: inClan1},
+			Clan2:       etf2l.PlayerResultClan{ID: 2, WasInTeam: !inClan1},
+			Competition: etf2l.MatchCompetition{ID: tier, Type: compType},
+			Division:    etf2l.Division{Name: fmt.Sprintf("Division %d", tier), Tier: tier},
+			R1:          r1,
+			R2:          r2,
+			Time:        unixTime,
+			Maps:        maps,
+		}
+	}
+
+	merc := result(400, 0, 3, false, "Highlander", 3, "pl_upward")
+	merc.Clan2.WasInTeam = false
+	merc.Merced = true
+	merc.Result = -1
+
+	transfer := func(teamID int, transferType string, unixTime int) etf2l.PlayerTransfer {
+		var playerTransfer etf2l.PlayerTransfer
+		playerTransfer.Team.ID = teamID
+		playerTransfer.Type = transferType
+		playerTransfer.Time = unixTime
+
+		return playerTransfer
+	}
+
+	career := etf2l.NewPlayerCareer(7, []etf2l.PlayerResult{
+		result(300, 2, 1, true, "6on6", 2, "cp_process_final", "cp_gullywash_final1"),
+		result(200, 1, 2, true, "6on6", 2, "cp_process_final"),
+		result(100, 3, 0, false, "6on6", 1, "koth_product_final"),
+		merc,
+	}, []etf2l.PlayerTransfer{
+		transfer(1, "left", 350),
+		transfer(1, "joined", 150),
+		transfer(2, "joined", 50),
+	})
+
+	require.Equal(t, etf2l.CareerRecord{Played: 4, Wins: 1, Losses: 3, MapsWon: 3, MapsLost: 9}, career.Overall)
+	require.Equal(t, etf2l.CareerRecord{Played: 3, Wins: 1, Losses: 2, MapsWon: 3, MapsLost: 6}, career.ByType["6on6"])
+	require.Equal(t, etf2l.CareerMap{Played: 2, Lost: 1}, career.Maps["cp_process_final"])
+	require.Equal(t, 1, career.MercAppearances)
+	require.Equal(t, time.Unix(100, 0), career.FirstMatch)
+	require.Equal(t, time.Unix(400, 0), career.LastMatch)
+	require.Len(t, career.Competitions, 3)
+
+	highest, found := career.HighestDivision()
+	require.True(t, found)
+	require.Equal(t, 1, highest.Tier)
+
+	require.Len(t, career.Teams, 2)
+	require.Equal(t, 2, career.Teams[0].TeamID)
+	require.True(t, career.Teams[0].Left.IsZero())
+	require.Equal(t, time.Unix(350, 0), career.Teams[1].Left)
+}
+
+func TestHeadToHead(t *testing.T) {
+	match := func(matchID int, clan1 int, clan2 int, r1 int, r2 int, unixTime int) etf2l.Match {
+		return etf2l.Match{
+			ID:        matchID,
+			Clan1:     etf2l.MatchClan{ID: clan1},
+			Clan2:     etf2l.MatchClan{ID: clan2},
+			R1:        r1,
+			R2:        r2,
+			Time:      unixTime,
+			Submitted: unixTime,
+		}
+	}
+
+	defaultWin := match(2, 2, 1, 3, 0, 100)
+	defaultWin.Defaultwin = true
+
+	scheduled := match(4, 1, 2, 0, 0, 900)
+	scheduled.Submitted = 0
+
+	details := etf2l.MatchDetails{
+		ID: 1, Clan1: etf2l.MatchClan{ID: 1}, Clan2: etf2l.MatchClan{ID: 2}, R1: 2, R2: 1, Time: 500, Submitted: 500,
+		MapResults: []etf2l.MatchMapResult{
+			{MatchOrder: 2, Clan1: 4, Clan2: 5, Map: "cp_process_final", GoldenCap: true},
+			{MatchOrder: 1, Clan1: 5, Clan2: 2, Map: "cp_gullywash_final1"},
+			{MatchOrder: 3, Clan1: 1, Clan2: 2, Map: "koth_product_final"},
+		},
+	}
+
+	server := etf2ltest.NewServer(etf2ltest.Fixtures{
+		Matches:      []etf2l.Match{match(1, 1, 2, 2, 1, 500), defaultWin, match(3, 1, 3, 5, 0, 300), scheduled},
+		MatchDetails: map[int]etf2l.MatchDetails{1: details},
+	})
+	defer server.Close()
+
+	h2h, err := server.NewClient().HeadToHead(context.Background(), server.Client(), 1, 2)
+	require.NoError(t, err)
+	require.Len(t, h2h.Meetings, 2)
+	require.Equal(t, 2, h2h.Meetings[0].MatchID)
+	require.Equal(t, etf2l.OutcomeLoss, h2h.Meetings[0].Outcome)
+	require.Equal(t, []string{"cp_gullywash_final1", "cp_process_final", "koth_product_final"}, []string{
+		h2h.Meetings[1].Maps[0].Map, h2h.Meetings[1].Maps[1].Map, h2h.Meetings[1].Maps[2].Map,
+	})
+	require.Equal(t, etf2l.CareerRecord{Played: 2, Wins: 1, Losses: 1, DefaultWins: 1, MapsWon: 2, MapsLost: 1}, h2h.Record)
+	require.Equal(t, etf2l.HeadToHeadMap{Played: 1, WinsB: 1, GoldenCaps: 1}, h2h.Maps["cp_process_final"])
+	require.Equal(t, 0, h2h.GoldenCapsA)
+	require.Equal(t, 1, h2h.GoldenCapsB)
+	require.Equal(t, 1, h2h.DefaultWinsB)
+}
+
+func TestCompetitionMapReport(t *testing.T) {
+	details := func(matchID int, clan1 int, clan2 int, results ...etf2l.MatchMapResult) etf2l.MatchDetails {
+		match := etf2l.MatchDetails{
+			ID: matchID, Clan1: etf2l.MatchClan{ID: clan1}, Clan2: etf2l.MatchClan{ID: clan2}, MapResults: results,
+		}
+
+		for _, result := range results {
+			match.Maps = append(match.Maps, result.Map)
+		}
+
+		return match
+	}
+
+	server := etf2ltest.NewServer(etf2ltest.Fixtures{
+		Competitions: []etf2l.CompetitionDetails{
+			{ID: 10, Pool: []string{"cp_process_final", "koth_product_final", "cp_sunshine"}},
+			{ID: 11, Pool: []string{"cp_process_final", "cp_granary_pro_rc8"}},
+		},
+		CompetitionMatches: map[int][]etf2l.CompetitionMatch{
+			10: {{ID: 1}, {ID: 2}, {ID: 3, Defaultwin: true}},
+			11: {{ID: 4}},
+		},
+		MatchDetails: map[int]etf2l.MatchDetails{
+			1: details(1, 1, 2,
+				etf2l.MatchMapResult{Map: "cp_process_final", Clan1: 5, Clan2: 4, GoldenCap: true},
+				etf2l.MatchMapResult{Map: "koth_product_final", Clan1: 1, Clan2: 3}),
+			2: details(2, 2, 3, etf2l.MatchMapResult{Map: "cp_process_final", Clan1: 5, Clan2: 0}),
+			4: details(4, 1, 3,
+				etf2l.MatchMapResult{Map: "cp_process_final", Clan1: 2, Clan2: 2},
+				etf2l.MatchMapResult{Map: "cp_badlands", Clan1: 0, Clan2: 5}),
+		},
+	})
+	defer server.Close()
+
+	report, err := server.NewClient().CompetitionMapReport(context.Background(), server.Client(), 10, 11)
+	require.NoError(t, err)
+	require.Equal(t, []string{"cp_sunshine", "cp_granary_pro_rc8"}, report.Unplayed)
+	require.Equal(t, []string{"cp_process_final", "cp_badlands", "koth_product_final"}, []string{
+		report.Maps[0].Map, report.Maps[1].Map, report.Maps[2].Map,
+	})
+
+	process, found := report.Map("cp_process_final")
+	require.True(t, found)
+	require.True(t, process.InPool)
+	require.Equal(t, 3, process.Played)
+	require.Equal(t, 2, process.Clan1Wins)
+	require.Equal(t, 1, process.Draws)
+	require.InDelta(t, 2.0/3.0, process.Clan1WinRate(), 0.0001)
+	require.InDelta(t, 1.0/3.0, process.GoldenCapRate(), 0.0001)
+	require.Equal(t, []etf2l.TeamMapRecord{
+		{TeamID: 1, Played: 2, Won: 1, Drawn: 1},
+		{TeamID: 2, Played: 2, Won: 1, Lost: 1},
+		{TeamID: 3, Played: 2, Lost: 1, Drawn: 1},
+	}, process.TeamRecords())
+
+	badlands, _ := report.Map("cp_badlands")
+	require.False(t, badlands.InPool)
+	require.Equal(t, 1, badlands.Clan2Wins)
+}
+
+func TestCompetitionBrackets(t *testing.T) {
+	match := func(matchID int, division int, round string, week int, clan1 int, clan2 int, r1 int, r2 int) etf2l.CompetitionMatch {
+		competitionMatch := etf2l.CompetitionMatch{ID: matchID, Round: round, Week: week}
+		competitionMatch.Division.ID = division
+		competitionMatch.Division.Tier = division
+		competitionMatch.Clan1.ID = clan1
+		competitionMatch.Clan2.ID = clan2
+		competitionMatch.Result.R1 = r1
+		competitionMatch.Result.R2 = r2
+
+		return competitionMatch
+	}
+
+	server := etf2ltest.NewServer(etf2ltest.Fixtures{
+		CompetitionMatches: map[int][]etf2l.CompetitionMatch{1: {
+			match(100, 1, "Week 1", 1, 1, 2, 3, 0),
+			match(1, 1, "Upper Bracket Semi Final", 8, 1, 4, 3, 0),
+			match(2, 1, "Upper Bracket Semi Final", 8, 2, 3, 0, 3),
+			match(3, 1, "Upper Bracket Final", 9, 1, 3, 2, 1),
+			match(4, 1, "Lower Bracket Round 1", 9, 4, 2, 1, 2),
+			match(5, 1, "Lower Bracket Final", 10, 3, 2, 3, 0),
+			match(6, 1, "Grand Final", 10, 1, 3, 0, 0),
+			match(7, 2, "Semi-Final", 8, 5, 6, 2, 1),
+			match(8, 2, "Final", 9, 5, 7, 0, 0),
+		}},
+	})
+	defer server.Close()
+
+	brackets, err := server.NewClient().CompetitionBrackets(context.Background(), server.Client(), 1)
+	require.NoError(t, err)
+	require.Len(t, brackets, 2)
+
+	double := brackets[0]
+	require.Equal(t, etf2l.DoubleElimination, double.Kind)
+	require.Equal(t, []string{"Upper Bracket Semi Final", "Upper Bracket Final"}, []string{double.Upper[0].Name, double.Upper[1].Name})
+	require.Len(t, double.Lower, 2)
+	require.Len(t, double.GrandFinal, 1)
+
+	links := map[int][2]int{1: {3, 4}, 2: {3, 4}, 3: {6, 5}, 4: {5, 0}, 5: {6, 0}}
+	for matchID, link := range links {
+		bracketMatch, found := double.Match(matchID)
+		require.True(t, found)
+		require.Equal(t, link, [2]int{bracketMatch.WinnerTo, bracketMatch.LoserTo}, matchID)
+	}
+
+	grandFinal, _ := double.Match(6)
+	require.False(t, grandFinal.Played)
+	require.Equal(t, []int{3, 5}, grandFinal.From)
+
+	single := brackets[1]
+	require.Equal(t, etf2l.SingleElimination, single.Kind)
+	require.Empty(t, single.Lower)
+
+	body, errJSON := json.Marshal(single)
+	require.NoError(t, errJSON)
+
+	var decoded etf2l.Bracket
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	semi, _ := decoded.Match(7)
+	require.Equal(t, 8, semi.WinnerTo)
+	require.Equal(t, 5, semi.Winner)
+}
+
+func TestTeamCalendar(t *testing.T) {
+	upcoming := etf2l.Match{
+		ID:          42,
+		Clan1:       etf2l.MatchClan{ID: 1, Name: "Team One"},
+		Clan2:       etf2l.MatchClan{ID: 2, Name: "Two; the, sequel"},
+		Competition: etf2l.MatchCompetition{Name: "Season 50"},
+		Division:    etf2l.Division{Name: "Premiership"},
+		Round:       "Week 3",
+		Maps:        []string{"cp_process_final", "koth_product_final"},
+		Time:        1700000000,
+	}
+	upcoming.Urls.Self = "https://etf2l.org/matches/42/"
+
+	played := upcoming
+	played.ID = 41
+	played.Submitted = 1690000000
+
+	server := etf2ltest.NewServer(etf2ltest.Fixtures{Matches: []etf2l.Match{played, upcoming}})
+	defer server.Close()
+
+	calendar, err := server.NewClient().TeamCalendar(context.Background(), server.Client(), 2)
+	require.NoError(t, err)
+	require.Len(t, calendar.Matches, 1)
+
+	calendar.Generated = time.Unix(1690000000, 0)
+
+	var out bytes.Buffer
+	_, errWrite := calendar.WriteTo(&out)
+	require.NoError(t, errWrite)
+
+	ics := out.String()
+	require.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\n"))
+	require.True(t, strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+	require.Contains(t, ics, "UID:"+etf2l.MatchUID(42)+"\r\n")
+	require.Contains(t, ics, "DTSTART:20231114T221320Z\r\n")
+	require.Contains(t, ics, "DTEND:20231115T001320Z\r\n")
+	require.Contains(t, ics, "SUMMARY:vs Team One\r\n")
+	require.Contains(t, ics, "LAST-MODIFIED:20230722T042640Z\r\n")
+	require.Contains(t, ics, "SEQUENCE:28166666\r\n")
+	require.Contains(t, strings.ReplaceAll(ics, "\r\n ", ""),
+		`DESCRIPTION:Competition: Season 50\nDivision: Premiership\nRound: Week 3\nMaps: cp_process_final\, koth_product_final`)
+	require.NotContains(t, ics, etf2l.MatchUID(41))
+
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		require.LessOrEqual(t, len(line), 75)
+	}
+
+	calendar.TeamID = 0
+	out.Reset()
+	_, errWrite = calendar.WriteTo(&out)
+	require.NoError(t, errWrite)
+	require.Contains(t, out.String(), `SUMMARY:Team One vs Two\; the\, sequel`)
+
+	// A later export of the same calendar is a newer revision of its events.
+	calendar.Generated = calendar.Generated.Add(time.Hour)
+	out.Reset()
+	_, errWrite = calendar.WriteTo(&out)
+	require.NoError(t, errWrite)
+	require.Contains(t, out.String(), "SEQUENCE:28166726\r\n")
+}
+
+func TestPlayerCalendar(t *testing.T) {
+	match := func(matchID int, clan1 int, clan2 int, at int) etf2l.Match {
+		return etf2l.Match{
+			ID:    matchID,
+			Clan1: etf2l.MatchClan{ID: clan1, Name: fmt.Sprintf("team%d", clan1)},
+			Clan2: etf2l.MatchClan{ID: clan2, Name: fmt.Sprintf("team%d", clan2)},
+			Time:  at,
+		}
+	}
+
+	player := etf2l.Player{ID: 7, Name: "b4nny", Teams: []etf2l.PlayerTeam{{ID: 1}, {ID: 2}}}
+	player.Steam.ID64 = testIDb4nny
+
+	server := etf2ltest.NewServer(etf2ltest.Fixtures{
+		Players: []etf2l.Player{player},
+		Matches: []etf2l.Match{
+			match(1, 1, 3, 1700003000),
+			match(2, 4, 2, 1700002000),
+			match(3, 1, 2, 1700001000),
+			match(4, 3, 4, 1700000000),
+		},
+	})
+	defer server.Close()
+
+	calendar, err := server.NewClient().PlayerCalendar(context.Background(), server.Client(), 7)
+	require.NoError(t, err)
+	require.Equal(t, "ETF2L matches of b4nny", calendar.Name)
+
+	var ids []int
+	for _, scheduled := range calendar.Matches {
+		ids = append(ids, scheduled.ID)
+	}
+
+	require.Equal(t, []int{3, 2, 1}, ids)
+}
+
+func TestDemoDownloader(t *testing.T) {
+	var (
+		served   = t.TempDir()
+		target   = t.TempDir()
+		contents = map[string][]byte{
+			"first.dem":  bytes.Repeat([]byte("HL2DEMO"), 1000),
+			"second.zip": bytes.Repeat([]byte("PK"), 5000),
+		}
+	)
+
+	for name, content := range contents {
+		require.NoError(t, os.WriteFile(filepath.Join(served, name), content, 0o600))
+	}
+
+	server := httptest.NewServer(http.FileServer(http.Dir(served)))
+	defer server.Close()
+
+	match := etf2l.MatchDetails{
+		ID:          77,
+		Competition: etf2l.MatchCompetition{ID: 5, Name: "Season 1: 6v6"},
+		Demos: []etf2l.Demo{
+			{ID: 1, DownloadURL: server.URL + "/first.dem", File: "first.dem"},
+			{ID: 2, DownloadURL: server.URL + "/second.zip", File: "second", Extension: "zip"},
+			{ID: 3, DownloadURL: server.URL + "/pruned.dem", Pruned: true},
+			{ID: 4, DownloadURL: server.URL + "/missing.dem", File: "missing.dem"},
+		},
+	}
+
+	downloader := etf2l.NewDemoDownloader(target, server.Client(), etf2l.WithDemoConcurrency(2), etf2l.WithDemoInterval(0))
+
+	// Simulate an interrupted download of the second demo.
+	secondPath := filepath.Join(target, "Season 1_ 6v6", "77", "second.zip")
+	require.NoError(t, os.MkdirAll(filepath.Dir(secondPath), 0o755))
+	require.NoError(t, os.WriteFile(secondPath+".part", contents["second.zip"][:3000], 0o600))
+
+	results, err := downloader.DownloadMatches(context.Background(), []etf2l.MatchDetails{match})
+	require.ErrorIs(t, err, etf2l.ErrNotFound)
+	require.Len(t, results, 4)
+
+	require.NoError(t, results[0].Err)
+	require.False(t, results[0].Resumed)
+	require.Equal(t, filepath.Join(target, "Season 1_ 6v6", "77", "first.dem"), results[0].Path)
+	require.Equal(t, int64(7000), results[0].Size)
+
+	sum := sha256.Sum256(contents["first.dem"])
+	require.Equal(t, hex.EncodeToString(sum[:]), results[0].SHA256)
+
+	require.NoError(t, results[1].Err)
+	require.True(t, results[1].Resumed)
+
+	second, errRead := os.ReadFile(secondPath)
+	require.NoError(t, errRead)
+	require.Equal(t, contents["second.zip"], second)
+
+	require.True(t, results[2].Skipped)
+	require.ErrorIs(t, results[3].Err, etf2l.ErrNotFound)
+
+	match.Demos = match.Demos[:1]
+	again, errAgain := downloader.DownloadMatches(context.Background(), []etf2l.MatchDetails{match})
+	require.NoError(t, errAgain)
+	require.True(t, again[0].Skipped)
+	require.Equal(t, results[0].SHA256, again[0].SHA256)
+
+	require.Equal(t, filepath.Join("unknown", "0", "first.dem"), downloader.DemoPath(match.Demos[0], etf2l.MatchCompetition{}))
+}
+
+func TestWhitelistWatcher(t *testing.T) {
+	var (
+		lastChange = 100
+		contents   = `// 6v6 whitelist
+"item_whitelist"
+{
+	"unlisted_items_default_to"		"0"
+	"The Direct Hit"	"1"
+	"The Gunboats"		"1"
+	"The Sandvich"		"0"
+	"Upgradeable TF_WEAPON_SHOTGUN_PRIMARY" "1"
+}
+`
+	)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	defer server.Close()
+
+	mux.HandleFunc("/whitelists", func(writer http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(writer).Encode(map[string]any{
+			"status": etf2l.Status{Code: 200, Message: "OK"},
+			"whitelists": map[string]etf2l.Whitelist{
+				"6v6": {Filename: "etf2l_whitelist_6v6.txt", LastChange: lastChange, URL: server.URL + "/files/6v6.txt"},
+			},
+		})
+	})
+	mux.HandleFunc("/files/6v6.txt", func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(contents))
+	})
+
+	watcher := etf2l.NewWhitelistWatcher(etf2l.New(etf2l.WithBaseURL(server.URL)), server.Client())
+
+	changes, err := watcher.Check(context.Background())
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, "6v6", changes[0].Name)
+	require.Equal(t, 0, changes[0].Previous.LastChange)
+	require.Equal(t, []string{"The Direct Hit", "The Gunboats", "Upgradeable TF_WEAPON_SHOTGUN_PRIMARY"}, changes[0].Diff.Added)
+
+	items := changes[0].Items
+	require.False(t, items.UnlistedItemsDefaultTo)
+	require.False(t, items.Allowed("The Sandvich"))
+	require.False(t, items.Allowed("The Mantreads"))
+	require.True(t, items.Allowed("The Gunboats"))
+
+	var out bytes.Buffer
+
+	_, errWrite := items.WriteTo(&out)
+	require.NoError(t, errWrite)
+
+	reparsed, errParse := etf2l.ParseItemWhitelist(&out)
+	require.NoError(t, errParse)
+	require.Equal(t, items, reparsed)
+
+	contents = strings.Replace(contents, `"The Gunboats"		"1"`, `"The Mantreads" "1"`, 1)
+
+	unchanged, errUnchanged := watcher.Check(context.Background())
+	require.NoError(t, errUnchanged)
+	require.Empty(t, unchanged)
+
+	lastChange = 200
+
+	changes, err = watcher.Check(context.Background())
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, 100, changes[0].Previous.LastChange)
+	require.Equal(t, etf2l.WhitelistDiff{Added: []string{"The Mantreads"}, Removed: []string{"The Gunboats"}}, changes[0].Diff)
+
+	_, errMissing := etf2l.ParseItemWhitelist(strings.NewReader(`"item_whitelist" { "a" "1"`))
+	require.Error(t, errMissing)
+
+	_, errBlock := etf2l.ParseItemWhitelist(strings.NewReader(`"whitelist" { }`))
+	require.Error(t, errBlock)
+}
+
+func TestWhitelistWatcherErrors(t *testing.T) {
+	var (
+		mu            sync.Mutex
+		listingFails  = false
+		downloadFails = true
+	)
+
+	failing := func(flag *bool) bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return *flag
+	}
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	defer server.Close()
+
+	mux.HandleFunc("/whitelists", func(writer http.ResponseWriter, _ *http.Request) {
+		if failing(&listingFails) {
+			writer.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		_ = json.NewEncoder(writer).Encode(map[string]any{
+			"status": etf2l.Status{Code: 200, Message: "OK"},
+			"whitelists": map[string]etf2l.Whitelist{
+				"6v6": {Filename: "etf2l_whitelist_6v6.txt", LastChange: 100, URL: server.URL + "/files/6v6.txt"},
+				"9v9": {Filename: "etf2l_whitelist_9v9.txt", LastChange: 100, URL: server.URL + "/files/9v9.txt"},
+			},
+		})
+	})
+	mux.HandleFunc("/files/6v6.txt", func(writer http.ResponseWriter, _ *http.Request) {
+		if failing(&downloadFails) {
+			writer.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		_, _ = writer.Write([]byte(`"item_whitelist" { "unlisted_items_default_to" "1" }`))
+	})
+	mux.HandleFunc("/files/9v9.txt", func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`"item_whitelist" { "unlisted_items_default_to" "0" }`))
+	})
+
+	watcher := etf2l.NewWhitelistWatcher(etf2l.New(etf2l.WithBaseURL(server.URL)), server.Client())
+
+	// The failed download does not prevent the other whitelists from being checked.
+	changes, err := watcher.Check(context.Background())
+	require.ErrorContains(t, err, "6v6")
+	require.Len(t, changes, 1)
+	require.Equal(t, "9v9", changes[0].Name)
+
+	mu.Lock()
+	downloadFails = false
+	mu.Unlock()
+
+	changes, err = watcher.Check(context.Background())
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, "6v6", changes[0].Name)
+
+	// Watch keeps polling after a failed check.
+	mu.Lock()
+	listingFails = true
+	mu.Unlock()
+
+	watcher = etf2l.NewWhitelistWatcher(etf2l.New(etf2l.WithBaseURL(server.URL)), server.Client())
+	watcher.Interval = time.Millisecond
+
+	var reported []error
+
+	watcher.OnError = func(err error) {
+		reported = append(reported, err)
+
+		mu.Lock()
+		listingFails = false
+		mu.Unlock()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var watched []string
+
+	errWatch := watcher.Watch(ctx, func(change etf2l.WhitelistChange) error {
+		watched = append(watched, change.Name)
+		if len(watched) == 2 {
+			cancel()
+		}
+
+		return nil
+	})
+	require.ErrorIs(t, errWatch, context.Canceled)
+	require.Len(t, reported, 1)
+	require.Equal(t, []string{"6v6", "9v9"}, watched)
+}
+
+func TestCompetitionConfigPack(t *testing.T) {
+	files := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`"item_whitelist" { "unlisted_items_default_to" "0" "The Gunboats" "1" "The Direct Hit" "1" }`))
+	}))
+	defer files.Close()
+
+	details := etf2l.CompetitionDetails{
+		ID:       7,
+		Name:     "6v6 Cup",
+		Category: "6v6 Cup",
+		Type:     "6on6",
+		Pool:     []string{"cp_process_final", "koth_product_final", "cp_gullywash_final1"},
+	}
+
+	server := etf2ltest.NewServer(etf2ltest.Fixtures{
+		Competitions: []etf2l.CompetitionDetails{details, {ID: 8, Type: "Highlander", Category: "Highlander Season"}, {ID: 9, Type: "1on1"}},
+		Whitelists: map[string]etf2l.Whitelist{
+			"6v6":     {Filename: "etf2l_whitelist_6v6.txt", URL: files.URL + "/6v6"},
+			"6v6_cup": {Filename: "etf2l_whitelist_6v6_cup.txt", URL: files.URL + "/6v6_cup"},
+		},
+	})
+	defer server.Close()
+
+	client := server.NewClient()
+
+	pack, err := client.CompetitionConfigPack(context.Background(), server.Client(), 7)
+	require.NoError(t, err)
+	require.Equal(t, "6v6", pack.Format.Name)
+	require.Equal(t, "etf2l_whitelist_6v6_cup.txt", pack.WhitelistFile)
+	require.Equal(t, []etf2l.GameMode{etf2l.ModeFiveCP, etf2l.ModeKOTH}, pack.Modes())
+
+	dir := t.TempDir()
+	require.NoError(t, pack.WriteDir(dir))
+
+	rendered, errFiles := pack.Files()
+	require.NoError(t, errFiles)
+
+	paths := make([]string, 0, len(rendered))
+	for _, file := range rendered {
+		paths = append(paths, file.Path)
+	}
+
+	require.Equal(t, []string{
+		"cfg/etf2l_6v6.cfg",
+		"cfg/etf2l_6v6_5cp.cfg",
+		"cfg/etf2l_6v6_koth.cfg",
+		"cfg/etf2l_whitelist_6v6_cup.txt",
+		"cfg/mapcycle_etf2l_6v6.txt",
+	}, paths)
+
+	base, errBase := os.ReadFile(filepath.Join(dir, "cfg", "etf2l_6v6.cfg"))
+	require.NoError(t, errBase)
+	require.Contains(t, string(base), "mp_tournament_whitelist \"cfg/etf2l_whitelist_6v6_cup.txt\"\n")
+	require.Contains(t, string(base), "tf_tournament_classlimit_demoman \"1\"\n")
+	require.Contains(t, string(base), "mapcyclefile \"mapcycle_etf2l_6v6.txt\"\n")
+
+	koth, errKoth := os.ReadFile(filepath.Join(dir, "cfg", "etf2l_6v6_koth.cfg"))
+	require.NoError(t, errKoth)
+	require.Contains(t, string(koth), "exec etf2l_6v6\nmp_timelimit \"0\"\nmp_winlimit \"4\"\n")
+
+	mapcycle, errCycle := os.ReadFile(filepath.Join(dir, "cfg", "mapcycle_etf2l_6v6.txt"))
+	require.NoError(t, errCycle)
+	require.Equal(t, "cp_process_final\nkoth_product_final\ncp_gullywash_final1\n", string(mapcycle))
+
+	again, errAgain := client.CompetitionConfigPack(context.Background(), server.Client(), 7)
+	require.NoError(t, errAgain)
+
+	renderedAgain, errFilesAgain := again.Files()
+	require.NoError(t, errFilesAgain)
+	require.Equal(t, rendered, renderedAgain)
+
+	_, errHighlander := client.CompetitionConfigPack(context.Background(), server.Client(), 8)
+	require.ErrorIs(t, errHighlander, etf2l.ErrNotFound)
+
+	_, errUnknown := client.CompetitionConfigPack(context.Background(), server.Client(), 9)
+	require.ErrorIs(t, errUnknown, etf2l.ErrUnknownFormat)
+}
+
+func TestRecruitmentShortlists(t *testing.T) {
+	var (
+		experienced = steamid.New("76561198203516436")
+		newcomer    = steamid.New("76561197970669109")
+	)
+
+	playerPost := func(id int, name string, sid steamid.SteamID, skill string, classes ...string) etf2l.PlayerRecruitment {
+		return etf2l.PlayerRecruitment{ID: id, Name: name, Skill: skill, Type: "6on6", Classes: classes, Steam: etf2l.SteamPlayer{ID64: sid}}
+	}
+
+	results := make([]etf2l.PlayerResult, 0, 12)
+	for range 12 {
+		results = append(results, etf2l.PlayerResult{
+			Competition: etf2l.MatchCompetition{Type: "6on6"},
+			Division:    etf2l.Division{Name: "Division 2", Tier: 3},
+		})
+	}
+
+	team := etf2l.TeamRecruitment{ID: 1, Name: "Froyotech", Skill: "Division 2", Type: "6on6", Classes: etf2l.PlayerClasses{"Medic", "Scout"}}
+	team.Urls.Team = "https://etf2l.org/teams/40/"
+	team.Steam.ID64 = experienced
+
+	server := etf2ltest.NewServer(etf2ltest.Fixtures{
+		Players: []etf2l.Player{
+			{ID: 1, Name: "veteran", Country: "Germany", Steam: etf2l.SteamPlayer{ID64: experienced}},
+			{ID: 2, Name: "rookie", Country: "France", Steam: etf2l.SteamPlayer{ID64: newcomer}},
+		},
+		PlayerResults: map[int][]etf2l.PlayerResult{1: results},
+		Teams:         []etf2l.Team{{ID: 40, Name: "Froyotech", Country: "Germany"}},
+		PlayerRecruitment: []etf2l.PlayerRecruitment{
+			playerPost(10, "rookie", newcomer, "Division 2", "Medic"),
+			playerPost(11, "veteran", experienced, "Division 3", "Medic", "Soldier"),
+			playerPost(12, "soldier main", experienced, "Division 2", "Soldier"),
+			{ID: 13, Name: "highlander", Skill: "Division 2", Type: "Highlander", Classes: etf2l.PlayerClasses{"Medic"}, Steam: etf2l.SteamPlayer{ID64: newcomer}},
+		},
+		TeamRecruitment: []etf2l.TeamRecruitment{team},
+	})
+	defer server.Close()
+
+	matcher := etf2l.NewRecruitmentMatcher()
+
+	shortlists, err := server.NewClient().RecruitmentShortlists(context.Background(), server.Client(), etf2l.RecruitmentOpts{}, matcher)
+	require.NoError(t, err)
+	require.Len(t, shortlists, 1)
+	require.Equal(t, 40, shortlists[0].Team.TeamID)
+	require.Equal(t, "Germany", shortlists[0].Team.Country)
+
+	candidates := shortlists[0].Candidates
+	require.Len(t, candidates, 2)
+
+	veteran := candidates[0]
+	require.Equal(t, 11, veteran.Player.Post.ID)
+	require.Equal(t, []string{"Medic"}, veteran.Classes)
+	require.Equal(t, -1, veteran.SkillGap)
+	require.Equal(t, 2, veteran.HighestTier)
+	require.Equal(t, 12, veteran.Matches)
+	require.True(t, veteran.SameCountry)
+
+	rookie := candidates[1]
+	require.Equal(t, 10, rookie.Player.Post.ID)
+	require.Equal(t, -1, rookie.HighestTier)
+	require.False(t, rookie.SameCountry)
+	require.Greater(t, veteran.Score, rookie.Score)
+	require.LessOrEqual(t, veteran.Score, 1.0)
+
+	filtered, errFiltered := server.NewClient().RecruitmentShortlists(context.Background(), server.Client(), etf2l.RecruitmentOpts{Skill: []string{"Division 2"}}, matcher)
+	require.NoError(t, errFiltered)
+	require.Len(t, filtered[0].Candidates, 1)
+	require.Equal(t, 10, filtered[0].Candidates[0].Player.Post.ID)
+
+	matcher.Limit = 1
+	require.Len(t, matcher.Match([]etf2l.RecruitingTeam{shortlists[0].Team}, []etf2l.RecruitingPlayer{veteran.Player, rookie.Player})[0].Candidates, 1)
+
+	tier, ok := etf2l.SkillTier("Premiership")
+	require.True(t, ok)
+	require.Equal(t, 0, tier)
+
+	_, ok = etf2l.SkillTier("Division 9")
+	require.False(t, ok)
+}
+
+func TestRecruitmentFeed(t *testing.T) {
+	sid := etf2l.SteamPlayer{ID64: steamid.New("76561198203516436")}
+	post := func(id int, name string, last int, classes ...string) etf2l.PlayerRecruitment {
+		recruitment := etf2l.PlayerRecruitment{
+			ID: id, Name: name, Skill: "Division 2", Type: "6on6", Classes: classes, Steam: sid,
+			Comments: etf2l.RecruitmentComments{Last: last},
+		}
+		recruitment.Urls.Recruitment = fmt.Sprintf("https://etf2l.org/recruitment/%d/", id)
+
+		return recruitment
+	}
+
+	team := etf2l.TeamRecruitment{ID: 5, Name: "Froyotech & co", Skill: "Open", Type: "Highlander", Classes: etf2l.PlayerClasses{"Spy"}, Steam: sid}
+
+	server := etf2ltest.NewServer(etf2ltest.Fixtures{
+		PlayerRecruitment: []etf2l.PlayerRecruitment{
+			post(1, "older medic", 1700000000, "Medic"),
+			post(2, "scout", 1700000500, "Scout"),
+			post(3, "newer medic", 1700001000, "Medic", "Soldier"),
+			post(4, "uncommented medic", 0, "Medic"),
+		},
+		TeamRecruitment: []etf2l.TeamRecruitment{team},
+	})
+	defer server.Close()
+
+	client := server.NewClient()
+
+	feed, err := client.PlayerRecruitmentFeed(context.Background(), server.Client(), etf2l.RecruitmentOpts{Class: []string{"Medic"}})
+	require.NoError(t, err)
+	require.Len(t, feed.Entries, 3)
+	require.Equal(t, etf2l.RecruitmentEntryID(etf2l.RecruitmentPlayers, 3), feed.Entries[0].ID)
+	require.Equal(t, "tag:etf2l.org,2009:recruitment/players/1", feed.Entries[1].ID)
+	require.Equal(t, "tag:etf2l.org,2009:recruitment/players/4", feed.Entries[2].ID)
+	require.True(t, feed.Entries[2].Updated.IsZero())
+	require.Equal(t, time.Unix(1700001000, 0).UTC(), feed.Updated())
+
+	feed.Generated = time.Unix(1700002000, 0)
+
+	var atom bytes.Buffer
+	require.NoError(t, feed.WriteAtom(&atom))
+
+	var parsedAtom struct {
+		Updated string `xml:"updated"`
+		Entries []struct {
+			ID      string `xml:"id"`
+			Updated string `xml:"updated"`
+			Link    struct {
+				Href string `xml:"href,attr"`
+			} `xml:"link"`
+			Categories []struct {
+				Term string `xml:"term,attr"`
+			} `xml:"category"`
+		} `xml:"entry"`
+	}
+
+	require.NoError(t, xml.Unmarshal(atom.Bytes(), &parsedAtom))
+	require.Contains(t, atom.String(), `<feed xmlns="http://www.w3.org/2005/Atom">`)
+	require.Equal(t, "2023-11-14T22:30:00Z", parsedAtom.Updated)
+	require.Len(t, parsedAtom.Entries, 3)
+	require.Equal(t, "https://etf2l.org/recruitment/3/", parsedAtom.Entries[0].Link.Href)
+	require.Equal(t, "2023-11-14T22:30:00Z", parsedAtom.Entries[0].Updated)
+	require.Equal(t, "2023-11-14T22:46:40Z", parsedAtom.Entries[2].Updated)
+	require.Len(t, parsedAtom.Entries[0].Categories, 4)
+
+	teams, errTeams := client.TeamRecruitmentFeed(context.Background(), server.Client(), etf2l.RecruitmentOpts{})
+	require.NoError(t, errTeams)
+
+	var jsonFeed bytes.Buffer
+	require.NoError(t, teams.WriteJSONFeed(&jsonFeed))
+
+	var parsedJSON struct {
+		Version string `json:"version"`
+		Items   []struct {
+			ID    string   `json:"id"`
+			Title string   `json:"title"`
+			Tags  []string `json:"tags"`
+		} `json:"items"`
+	}
+
+	require.NoError(t, json.Unmarshal(jsonFeed.Bytes(), &parsedJSON))
+	require.Equal(t, "https://jsonfeed.org/version/1.1", parsedJSON.Version)
+	require.Len(t, parsedJSON.Items, 1)
+	require.Equal(t, "tag:etf2l.org,2009:recruitment/teams/5", parsedJSON.Items[0].ID)
+	require.Equal(t, "Froyotech & co is looking for Spy (Highlander)", parsedJSON.Items[0].Title)
+	require.Equal(t, []string{"Spy", "Open", "Highlander"}, parsedJSON.Items[0].Tags)
+
+	empty := etf2l.NewTeamRecruitmentFeed(nil)
+	empty.Generated = time.Unix(1600000000, 0)
+	require.Equal(t, empty.Generated, empty.Updated())
+}
+
+func TestRecruitmentPoller(t *testing.T) {
+	sid := etf2l.SteamPlayer{ID64: steamid.New("76561198203516436")}
+	player := func(id int, count int) etf2l.PlayerRecruitment {
+		return etf2l.PlayerRecruitment{
+			ID: id, Name: fmt.Sprintf("player %d", id), Type: "6on6", Steam: sid,
+			Comments: etf2l.RecruitmentComments{Count: count, Last: 1700000000 + count},
+		}
+	}
+
+	team := etf2l.TeamRecruitment{ID: 1, Name: "Froyotech", Type: "6on6", Steam: sid}
+	before := etf2ltest.NewServer(etf2ltest.Fixtures{
+		PlayerRecruitment: []etf2l.PlayerRecruitment{player(1, 0), player(2, 0), player(3, 1)},
+		TeamRecruitment:   []etf2l.TeamRecruitment{team},
+	})
+	defer before.Close()
+
+	after := etf2ltest.NewServer(etf2ltest.Fixtures{
+		PlayerRecruitment: []etf2l.PlayerRecruitment{player(4, 0), player(2, 3), player(3, 1)},
+		TeamRecruitment:   []etf2l.TeamRecruitment{team},
+	})
+	defer after.Close()
+
+	store := etf2l.NewFileRecruitmentStore(filepath.Join(t.TempDir(), "recruitment.json"))
+
+	initial, err := etf2l.NewRecruitmentPoller(before.NewClient(), before.Client(), store).Poll(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, initial)
+
+	snapshot, errLoad := store.LoadRecruitment(context.Background())
+	require.NoError(t, errLoad)
+	require.Len(t, snapshot.Players, 3)
+	require.Equal(t, "Froyotech", snapshot.Teams[1].Name)
+
+	events, errPoll := etf2l.NewRecruitmentPoller(after.NewClient(), after.Client(), store).Poll(context.Background())
+	require.NoError(t, errPoll)
+	require.Len(t, events, 3)
+
+	require.Equal(t, etf2l.RecruitmentPostRemoved, events[0].Kind)
+	require.Equal(t, 1, events[0].PostID)
+	require.Equal(t, "player 1", events[0].State.Name)
+	require.Nil(t, events[0].Player)
+
+	require.Equal(t, etf2l.RecruitmentPostCommented, events[1].Kind)
+	require.Equal(t, 2, events[1].PostID)
+	require.Equal(t, 0, events[1].State.Comments.Count)
+	require.Equal(t, 3, events[1].Player.Comments.Count)
+
+	require.Equal(t, etf2l.RecruitmentPostAdded, events[2].Kind)
+	require.Equal(t, etf2l.RecruitmentPlayers, events[2].PostKind)
+	require.Equal(t, "player 4", events[2].Player.Name)
+
+	poller := etf2l.NewRecruitmentPoller(after.NewClient(), after.Client(), &etf2l.MemoryRecruitmentStore{})
+	poller.EmitInitial = true
+
+	var handled []etf2l.RecruitmentEvent
+
+	errHandler := errors.New("handler failed")
+	errRun := poller.Run(context.Background(), func(event etf2l.RecruitmentEvent) error {
+		handled = append(handled, event)
+
+		return errHandler
+	})
+	require.ErrorIs(t, errRun, errHandler)
+	require.Len(t, handled, 1)
+
+	// The failed run did not save, so the events are delivered again.
+	redelivered, errRedeliver := poller.Poll(context.Background())
+	require.NoError(t, errRedeliver)
+	require.Len(t, redelivered, 4)
+	require.Equal(t, etf2l.RecruitmentTeams, redelivered[3].PostKind)
+	require.NotNil(t, redelivered[3].Team)
+
+	// Failed polls are reported and retried on the next tick.
+	flaky := &flakyRecruitmentStore{failures: 2}
+	poller = etf2l.NewRecruitmentPoller(after.NewClient(), after.Client(), flaky)
+	poller.EmitInitial = true
+	poller.Interval = time.Millisecond
+
+	var reported []error
+
+	poller.OnError = func(err error) {
+		reported = append(reported, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	handled = nil
+	errRun = poller.Run(ctx, func(event etf2l.RecruitmentEvent) error {
+		handled = append(handled, event)
+		if len(handled) == 4 {
+			cancel()
+		}
+
+		return nil
+	})
+	require.ErrorIs(t, errRun, context.Canceled)
+	require.Len(t, reported, 2)
+	require.ErrorIs(t, reported[0], errFlakyStore)
+	require.Len(t, handled, 4)
+}
+
+var errFlakyStore = errors.New("store unavailable")
+
+// flakyRecruitmentStore fails to load the snapshot a number of times before succeeding.
+type flakyRecruitmentStore struct {
+	etf2l.MemoryRecruitmentStore
+	failures int
+}
+
+func (s *flakyRecruitmentStore) LoadRecruitment(ctx context.Context) (etf2l.RecruitmentSnapshot, error) {
+	if s.failures > 0 {
+		s.failures--
+
+		return etf2l.RecruitmentSnapshot{}, errFlakyStore
+	}
+
+	return s.MemoryRecruitmentStore.LoadRecruitment(ctx)
+}
+
+func TestComputeStandings(t *testing.T) {
+	team := func(teamID int) etf2l.StandingsTeam {
+		return etf2l.StandingsTeam{ID: teamID, Name: fmt.Sprintf("team%d", teamID)}
+	}
+
+	match := func(clan1 int, clan2 int, r1 int, r2 int) etf2l.StandingsMatch {
+		return etf2l.StandingsMatch{DivisionID: 1, Clan1: team(clan1), Clan2: team(clan2), R1: r1, R2: r2}
+	}
+
+	goldenCap := match(1, 3, 1, 1)
+	goldenCap.MapResults = []etf2l.MatchMapResult{
+		{MatchOrder: 1, Clan1: 5, Clan2: 0, Map: "cp_process_final"},
+		{MatchOrder: 2, Clan1: 4, Clan2: 5, Map: "cp_gullywash_final1", GoldenCap: true},
+	}
+
+	rows := etf2l.ComputeStandings([]etf2l.StandingsMatch{
+		match(1, 2, 2, 0),
+		match(2, 3, 2, 0),
+		goldenCap,
+		match(4, 0, 0, 0),
+		match(1, 4, 0, 0),
+	}, etf2l.StandingsConfig{
+		Scoring:     etf2l.DefaultScoring(),
+		Adjustments: map[int]etf2l.StandingsAdjustment{2: {PenaltyPoints: 1}},
+	})
+
+	require.Len(t, rows, 4)
+
+	positions := map[int]int{}
+	scores := map[int]int{}
+
+	for _, row := range rows {
+		positions[row.Team.ID] = row.Position
+		scores[row.Team.ID] = row.Score
+	}
+
+	require.Equal(t, map[int]int{1: 10, 2: 5, 3: 2, 4: 3}, scores)
+	require.Equal(t, map[int]int{1: 1, 2: 2, 4: 3, 3: 4}, positions)
+	require.Equal(t, 1, rows[3].GcWon)
+
+	diffs := etf2l.DiffStandings(rows, map[string]etf2l.CompetitionTable{
+		"1": {TeamID: 1, Name: "team1", MapsPlayed: 4, MapsWon: 3, MapsLost: 1, GcLost: 1, Score: 10},
+		"2": {TeamID: 2, Name: "team2", MapsPlayed: 4, MapsWon: 2, MapsLost: 2, Score: 6, PenaltyPoints: 1},
+		"3": {TeamID: 3, Name: "team3", MapsPlayed: 4, MapsWon: 1, MapsLost: 3, Score: 2, GcWon: 1},
+		"5": {TeamID: 5, Name: "team5"},
+	})
+	require.Equal(t, []etf2l.StandingsDiff{
+		{TeamID: 2, Name: "team2", Field: "score", Computed: 5, Official: 6},
+		{TeamID: 4, Name: "team4", Field: "missing", Computed: 1},
+		{TeamID: 5, Name: "team5", Field: "missing", Official: 1},
+	}, diffs)
+
+	// team1 beats team2, team2 beats team3 and team3 beats team1, which leaves all three level on points.
+	cycle := []etf2l.StandingsMatch{
+		match(1, 2, 2, 0), match(2, 3, 2, 0), match(3, 1, 2, 0),
+		match(1, 4, 2, 0), match(2, 4, 2, 1), match(3, 4, 2, 0),
+	}
+
+	table := func(matches []etf2l.StandingsMatch) [][2]int {
+		var positions [][2]int
+		for _, row := range etf2l.ComputeStandings(matches, etf2l.StandingsConfig{Scoring: etf2l.DefaultScoring()}) {
+			positions = append(positions, [2]int{row.Team.ID, row.Position})
+		}
+
+		return positions
+	}
+
+	// The head-to-head mini table cannot separate the three, map difference puts team2 last and the head-to-head
+	// between the remaining two puts team3 first.
+	expected := [][2]int{{3, 1}, {1, 2}, {2, 3}, {4, 4}}
+	require.Equal(t, expected, table(cycle))
+
+	reversed := slices.Clone(cycle)
+	slices.Reverse(reversed)
+	require.Equal(t, expected, table(reversed))
+
+	require.Equal(t, [][2]int{{1, 1}, {2, 1}, {3, 1}}, table(cycle[:3]))
+	require.Equal(t, [][2]int{{1, 1}, {2, 1}, {3, 1}}, table(reversed[3:]))
+}
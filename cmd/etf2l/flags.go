@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// bindOpts registers a flag for every field of an opts struct carrying a url tag, the flag name is the query
+// parameter name. Embedded fields such as BaseOpts are skipped, paging is controlled by the -all flag instead.
+func bindOpts(flags *flag.FlagSet, opts any) {
+	value := reflect.ValueOf(opts).Elem()
+
+	for idx := range value.NumField() {
+		field := value.Type().Field(idx)
+		if field.Anonymous || !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("url"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		target := value.Field(idx)
+		usage := fmt.Sprintf("Filter by %s", name)
+
+		switch target.Kind() {
+		case reflect.Int, reflect.Int64:
+			flags.Func(name, usage+" (integer)", func(raw string) error {
+				parsed, err := strconv.ParseInt(raw, 10, 64)
+				if err != nil {
+					return err
+				}
+
+				target.SetInt(parsed)
+
+				return nil
+			})
+		case reflect.String:
+			flags.Func(name, usage, func(raw string) error {
+				target.SetString(raw)
+
+				return nil
+			})
+		case reflect.Bool:
+			flags.BoolFunc(name, usage, func(raw string) error {
+				parsed, err := strconv.ParseBool(raw)
+				if err != nil {
+					return err
+				}
+
+				target.SetBool(parsed)
+
+				return nil
+			})
+		case reflect.Slice:
+			flags.Func(name, usage+" (comma separated, repeatable)", func(raw string) error {
+				for _, item := range strings.Split(raw, ",") {
+					target.Set(reflect.Append(target, reflect.ValueOf(strings.TrimSpace(item))))
+				}
+
+				return nil
+			})
+		default:
+			panic(fmt.Sprintf("unsupported opts field type: %s", target.Kind()))
+		}
+	}
+}
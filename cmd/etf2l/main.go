@@ -0,0 +1,324 @@
+// Command etf2l queries the ETF2L api from the command line.
+//
+// Usage:
+//
+//	etf2l [global flags] <command> [flags] [id]
+//
+// Run etf2l without arguments to list the available commands.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leighmacdonald/etf2l"
+)
+
+var (
+	errUsage = errors.New("invalid usage")
+	// errReported is returned when the flag package has already printed the error and usage.
+	errReported = errors.New("invalid flags")
+)
+
+// env holds the state shared by every command.
+type env struct {
+	client     *etf2l.Client
+	httpClient etf2l.HTTPExecutor
+	all        bool
+}
+
+// recursive returns the paging options selected with the -all flag.
+func (e env) recursive() etf2l.BaseOpts {
+	return etf2l.BaseOpts{Recursive: e.all}
+}
+
+type command struct {
+	name  string
+	usage string
+	// opts is bound to the command flags, it is nil for commands without options.
+	opts any
+	// flags registers flags which are not api filters, it is nil for most commands.
+	flags func(flags *flag.FlagSet)
+	hasID bool
+	// paged commands list results spread over several pages and accept the -all flag.
+	paged bool
+	run   func(ctx context.Context, env env, id int) (output, error)
+}
+
+// writtenFile is the output row of the competition configs command.
+type writtenFile struct {
+	Path string `json:"path"`
+	Size int    `json:"size"`
+}
+
+func writeConfigPack(ctx context.Context, env env, competitionID int, dir string) ([]writtenFile, error) {
+	pack, errPack := env.client.CompetitionConfigPack(ctx, env.httpClient, competitionID)
+	if errPack != nil {
+		return nil, errPack
+	}
+
+	files, errFiles := pack.Files()
+	if errFiles != nil {
+		return nil, errFiles
+	}
+
+	if dir == "" {
+		dir = "."
+	}
+
+	if err := pack.WriteDir(dir); err != nil {
+		return nil, err
+	}
+
+	written := make([]writtenFile, 0, len(files))
+	for _, file := range files {
+		written = append(written, writtenFile{Path: filepath.Join(dir, filepath.FromSlash(file.Path)), Size: len(file.Content)})
+	}
+
+	return written, nil
+}
+
+func commands() []*command {
+	var (
+		bans        etf2l.BanOpts
+		demos       etf2l.DemoOpts
+		competition etf2l.CompetitionOpts
+		matches     etf2l.MatchesOpts
+		recruitment etf2l.RecruitmentOpts
+		configDir   string
+	)
+
+	return []*command{
+		{name: "player", usage: "Show a player profile", hasID: true, run: func(ctx context.Context, env env, id int) (output, error) {
+			return one(env.client.Player(ctx, env.httpClient, strconv.Itoa(id)))
+		}},
+		{name: "player results", usage: "List the results of a player", hasID: true, paged: true, run: func(ctx context.Context, env env, id int) (output, error) {
+			return many(env.client.PlayerResults(ctx, env.httpClient, strconv.Itoa(id), env.recursive()))
+		}},
+		{name: "player transfers", usage: "List the transfers of a player", hasID: true, paged: true, run: func(ctx context.Context, env env, id int) (output, error) {
+			return many(env.client.PlayerTransfers(ctx, env.httpClient, id, env.recursive()))
+		}},
+		{name: "team", usage: "Show a team profile", hasID: true, run: func(ctx context.Context, env env, id int) (output, error) {
+			return one(env.client.Team(ctx, env.httpClient, id))
+		}},
+		{name: "team transfers", usage: "List the transfers of a team", hasID: true, paged: true, run: func(ctx context.Context, env env, id int) (output, error) {
+			return many(env.client.TeamTransfers(ctx, env.httpClient, id, env.recursive()))
+		}},
+		{name: "team results", usage: "List the results of a team", hasID: true, paged: true, run: func(ctx context.Context, env env, id int) (output, error) {
+			return many(env.client.TeamResults(ctx, env.httpClient, id, env.recursive()))
+		}},
+		{name: "bans", usage: "List bans", opts: &bans, paged: true, run: func(ctx context.Context, env env, _ int) (output, error) {
+			bans.Recursive = env.recursive()
+
+			return many(env.client.Bans(ctx, env.httpClient, bans))
+		}},
+		{name: "demos", usage: "List demos", opts: &demos, paged: true, run: func(ctx context.Context, env env, _ int) (output, error) {
+			demos.Recursive = env.recursive()
+
+			return many(env.client.Demos(ctx, env.httpClient, demos))
+		}},
+		{name: "competitions", usage: "List competitions", opts: &competition, paged: true, run: func(ctx context.Context, env env, _ int) (output, error) {
+			competition.Recursive = env.recursive()
+
+			return many(env.client.CompetitionList(ctx, env.httpClient, competition))
+		}},
+		{name: "competition", usage: "Show a competition", hasID: true, run: func(ctx context.Context, env env, id int) (output, error) {
+			return one(env.client.CompetitionDetails(ctx, env.httpClient, id))
+		}},
+		{name: "competition tables", usage: "Show the tables of a competition", hasID: true, run: func(ctx context.Context, env env, id int) (output, error) {
+			return entries(env.client.CompetitionTables(ctx, env.httpClient, id))
+		}},
+		{name: "competition teams", usage: "List the teams of a competition", hasID: true, paged: true, run: func(ctx context.Context, env env, id int) (output, error) {
+			return many(env.client.CompetitionTeams(ctx, env.httpClient, id, env.recursive()))
+		}},
+		{name: "competition results", usage: "List the results of a competition", hasID: true, paged: true, run: func(ctx context.Context, env env, id int) (output, error) {
+			return many(env.client.CompetitionResults(ctx, env.httpClient, id, env.recursive()))
+		}},
+		{name: "competition matches", usage: "List the matches of a competition", hasID: true, paged: true, run: func(ctx context.Context, env env, id int) (output, error) {
+			return many(env.client.CompetitionMatches(ctx, env.httpClient, id, env.recursive()))
+		}},
+		{name: "competition configs", usage: "Write the server config pack of a competition", hasID: true, flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&configDir, "dir", ".", "Directory to write the configs to, usually the tf directory of a server")
+		}, run: func(ctx context.Context, env env, id int) (output, error) {
+			return many(writeConfigPack(ctx, env, id, configDir))
+		}},
+		{name: "matches", usage: "List matches", opts: &matches, paged: true, run: func(ctx context.Context, env env, _ int) (output, error) {
+			matches.BaseOpts = env.recursive()
+			results, _, err := env.client.Matches(ctx, env.httpClient, matches)
+
+			return many(results, err)
+		}},
+		{name: "match", usage: "Show a match", hasID: true, run: func(ctx context.Context, env env, id int) (output, error) {
+			return one(env.client.MatchDetails(ctx, env.httpClient, id))
+		}},
+		{name: "whitelists", usage: "List whitelists", run: func(ctx context.Context, env env, _ int) (output, error) {
+			return entries(env.client.Whitelists(ctx, env.httpClient))
+		}},
+		{name: "recruitment", usage: "List player recruitment posts", opts: &recruitment, paged: true, run: func(ctx context.Context, env env, _ int) (output, error) {
+			recruitment.BaseOpts = env.recursive()
+
+			return many(env.client.PlayerRecruitment(ctx, env.httpClient, recruitment))
+		}},
+		{name: "recruitment teams", usage: "List team recruitment posts", opts: &recruitment, paged: true, run: func(ctx context.Context, env env, _ int) (output, error) {
+			recruitment.BaseOpts = env.recursive()
+
+			return many(env.client.TeamRecruitment(ctx, env.httpClient, recruitment))
+		}},
+	}
+}
+
+// lookup finds the command named by the leading arguments, preferring the longest match, eg: "player results".
+func lookup(cmds []*command, args []string) (*command, []string) {
+	if len(args) >= 2 {
+		for _, cmd := range cmds {
+			if cmd.name == args[0]+" "+args[1] {
+				return cmd, args[2:]
+			}
+		}
+	}
+
+	if len(args) >= 1 {
+		for _, cmd := range cmds {
+			if cmd.name == args[0] {
+				return cmd, args[1:]
+			}
+		}
+	}
+
+	return nil, args
+}
+
+func usage(writer io.Writer, global *flag.FlagSet, cmds []*command) {
+	_, _ = fmt.Fprintf(writer, "Usage: etf2l [global flags] <command> [flags] [id]\n\nCommands:\n")
+
+	for _, cmd := range cmds {
+		name := cmd.name
+		if cmd.hasID {
+			name += " <id>"
+		}
+
+		_, _ = fmt.Fprintf(writer, "  %-28s %s\n", name, cmd.usage)
+	}
+
+	_, _ = fmt.Fprintf(writer, "\nGlobal flags:\n")
+	global.SetOutput(writer)
+	global.PrintDefaults()
+}
+
+func parseError(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+
+	return errors.Join(err, errReported)
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	var (
+		cmds    = commands()
+		global  = flag.NewFlagSet("etf2l", flag.ContinueOnError)
+		format  = global.String("format", formatTable, "Output format: table, json, ndjson or csv")
+		columns = global.String("columns", "", "Comma separated columns to include in table and csv output, eg: id,name,clan1.name")
+		baseURL = global.String("base-url", etf2l.DefaultBaseURL, "Base URL of the api")
+		timeout = global.Duration("timeout", time.Minute, "Timeout for the whole command")
+	)
+
+	global.SetOutput(stderr)
+	global.Usage = func() { usage(stderr, global, cmds) }
+
+	if err := global.Parse(args); err != nil {
+		return parseError(err)
+	}
+
+	cmd, rest := lookup(cmds, global.Args())
+	if cmd == nil {
+		usage(stderr, global, cmds)
+
+		return errReported
+	}
+
+	var (
+		cmdEnv = env{client: etf2l.New(etf2l.WithBaseURL(*baseURL)), httpClient: &http.Client{}}
+		flags  = flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	)
+
+	flags.SetOutput(stderr)
+	if cmd.paged {
+		flags.BoolVar(&cmdEnv.all, "all", false, "Fetch every page instead of only the first")
+	}
+
+	if cmd.opts != nil {
+		bindOpts(flags, cmd.opts)
+	}
+
+	if cmd.flags != nil {
+		cmd.flags(flags)
+	}
+
+	if err := flags.Parse(rest); err != nil {
+		return parseError(err)
+	}
+
+	var id int
+
+	if cmd.hasID {
+		if flags.NArg() != 1 {
+			return fmt.Errorf("%w: %s requires an id", errUsage, cmd.name)
+		}
+
+		parsed, errParse := strconv.Atoi(flags.Arg(0))
+		if errParse != nil {
+			return fmt.Errorf("%w: invalid id %s", errUsage, flags.Arg(0))
+		}
+
+		id = parsed
+	} else if flags.NArg() != 0 {
+		return fmt.Errorf("%w: unexpected arguments %s", errUsage, strings.Join(flags.Args(), " "))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	result, errRun := cmd.run(ctx, cmdEnv, id)
+	if errRun != nil {
+		return errRun
+	}
+
+	var selected []string
+	if *columns != "" {
+		selected = strings.Split(*columns, ",")
+	}
+
+	return result.render(stdout, *format, selected)
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errReported):
+		os.Exit(2)
+	case errors.Is(err, errUsage):
+		_, _ = fmt.Fprintln(os.Stderr, err)
+
+		os.Exit(2)
+	default:
+		_, _ = fmt.Fprintln(os.Stderr, err)
+
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leighmacdonald/etf2l"
+	"github.com/leighmacdonald/etf2l/etf2ltest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	files := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`"item_whitelist" { "unlisted_items_default_to" "0" "The Direct Hit" "1" }`))
+	}))
+	defer files.Close()
+
+	server := etf2ltest.NewServer(etf2ltest.Fixtures{
+		Matches: []etf2l.Match{
+			{ID: 1, Clan1: etf2l.MatchClan{ID: 1, Name: "one"}, Clan2: etf2l.MatchClan{ID: 2, Name: "two"}, Maps: []string{"cp_process_final", "koth_product_final"}},
+			{ID: 2, Clan1: etf2l.MatchClan{ID: 3, Name: "three"}, Clan2: etf2l.MatchClan{ID: 1, Name: "one"}},
+		},
+		Competitions: []etf2l.CompetitionDetails{{ID: 4, Name: "Bball Cup", Type: "Bball", Pool: []string{"ctf_ballin_sky"}}},
+		Whitelists: map[string]etf2l.Whitelist{
+			"6v6":   {Filename: "etf2l_whitelist_6v6.txt"},
+			"9v9":   {Filename: "etf2l_whitelist_9v9.txt"},
+			"bball": {Filename: "etf2l_whitelist_bball.txt", URL: files.URL + "/bball"},
+		},
+	})
+	defer server.Close()
+
+	execute := func(args ...string) (string, error) {
+		var stdout, stderr bytes.Buffer
+
+		err := run(context.Background(), append([]string{"-base-url", server.URL}, args...), &stdout, &stderr)
+
+		return stdout.String(), err
+	}
+
+	out, errCSV := execute("-format", "csv", "-columns", "id,clan1.name,maps", "matches", "-vs", "2")
+	require.NoError(t, errCSV)
+
+	records, errRead := csv.NewReader(strings.NewReader(out)).ReadAll()
+	require.NoError(t, errRead)
+	require.Equal(t, [][]string{{"id", "clan1.name", "maps"}, {"1", "one", "cp_process_final;koth_product_final"}}, records)
+
+	out, errNDJSON := execute("-format", "ndjson", "whitelists")
+	require.NoError(t, errNDJSON)
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 3)
+
+	var entry struct {
+		Key   string          `json:"key"`
+		Value etf2l.Whitelist `json:"value"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	require.Equal(t, "9v9", entry.Key)
+	require.Equal(t, "etf2l_whitelist_9v9.txt", entry.Value.Filename)
+
+	out, errTable := execute("match", "2")
+	require.NoError(t, errTable)
+	require.Contains(t, out, "clan1.name")
+	require.Contains(t, out, "three")
+
+	dir := t.TempDir()
+	out, errConfigs := execute("-format", "csv", "-columns", "path", "competition", "configs", "-dir", dir, "4")
+	require.NoError(t, errConfigs)
+	require.Equal(t, "path\n"+strings.Join([]string{
+		filepath.Join(dir, "cfg", "etf2l_bball.cfg"),
+		filepath.Join(dir, "cfg", "etf2l_bball_ctf.cfg"),
+		filepath.Join(dir, "cfg", "etf2l_whitelist_bball.txt"),
+		filepath.Join(dir, "cfg", "mapcycle_etf2l_bball.txt"),
+	}, "\n")+"\n", out)
+	require.FileExists(t, filepath.Join(dir, "cfg", "etf2l_bball_ctf.cfg"))
+
+	_, errAll := execute("matches", "-all")
+	require.NoError(t, errAll)
+
+	_, errSingle := execute("match", "-all", "2")
+	require.ErrorIs(t, errSingle, errReported)
+
+	_, errID := execute("match", "abc")
+	require.ErrorIs(t, errID, errUsage)
+
+	_, errCommand := execute("unknown")
+	require.ErrorIs(t, errCommand, errReported)
+
+	_, errXML := execute("-format", "xml", "whitelists")
+	require.ErrorIs(t, errXML, errFormat)
+
+	_, errUnknown := execute("-columns", "key,nope", "whitelists")
+	require.ErrorIs(t, errUnknown, errColumn)
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/leighmacdonald/etf2l/export"
+)
+
+const (
+	formatTable  = "table"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+	formatCSV    = "csv"
+)
+
+var (
+	errFormat = errors.New("unknown output format")
+	errColumn = errors.New("unknown column")
+)
+
+// output is the result of a command.
+type output interface {
+	// render writes the result in the requested format. Columns limits the table and csv output to the given
+	// columns.
+	render(writer io.Writer, format string, columns []string) error
+}
+
+// rows is a result made of rows of type T, flattened into columns by the export package.
+type rows[T any] struct {
+	// value is the result as returned by the client, used for json output.
+	value any
+	rows  []T
+	// single results are written as one field per line in table format.
+	single bool
+}
+
+// entry is a row of a result keyed by name, eg: the whitelists.
+type entry[V any] struct {
+	Key   string `json:"key"`
+	Value V      `json:"value"`
+}
+
+func one[T any](row T, err error) (output, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return rows[T]{value: row, rows: []T{row}, single: true}, nil
+}
+
+func many[T any](items []T, err error) (output, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return rows[T]{value: items, rows: items}, nil
+}
+
+// entries produces one row per map entry, ordered by key.
+func entries[V any](items map[string]V, err error) (output, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	keyed := make([]entry[V], 0, len(items))
+	for _, key := range slices.Sorted(maps.Keys(items)) {
+		keyed = append(keyed, entry[V]{Key: key, Value: items[key]})
+	}
+
+	return rows[entry[V]]{value: items, rows: keyed}, nil
+}
+
+func (r rows[T]) render(writer io.Writer, format string, columns []string) error {
+	switch format {
+	case formatJSON:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(r.value)
+	case formatNDJSON:
+		return export.Write(writer, export.NDJSON, slices.Values(r.rows))
+	case formatCSV:
+		header, records, errFlatten := r.flatten(columns)
+		if errFlatten != nil {
+			return errFlatten
+		}
+
+		return writeCSV(writer, header, records)
+	case formatTable:
+		header, records, errFlatten := r.flatten(columns)
+		if errFlatten != nil {
+			return errFlatten
+		}
+
+		if r.single && len(records) == 1 {
+			return writeVertical(writer, header, records[0])
+		}
+
+		return writeTable(writer, header, records)
+	default:
+		return fmt.Errorf("%w: %s", errFormat, format)
+	}
+}
+
+// flatten converts the rows into the records written by the export csv writer, limited to the given columns.
+func (r rows[T]) flatten(columns []string) ([]string, [][]string, error) {
+	header, errColumns := export.Columns[T]()
+	if errColumns != nil {
+		return nil, nil, errColumns
+	}
+
+	var buf bytes.Buffer
+	if err := export.Write(&buf, export.CSV, slices.Values(r.rows)); err != nil {
+		return nil, nil, err
+	}
+
+	records, errRead := csv.NewReader(&buf).ReadAll()
+	if errRead != nil {
+		return nil, nil, errRead
+	}
+
+	// Skip the header row, it matches the column names.
+	records = records[1:]
+
+	if len(columns) == 0 {
+		return header, records, nil
+	}
+
+	indexes := make([]int, len(columns))
+
+	for idx, column := range columns {
+		indexes[idx] = slices.Index(header, column)
+		if indexes[idx] < 0 {
+			return nil, nil, fmt.Errorf("%w: %s", errColumn, column)
+		}
+	}
+
+	selected := make([][]string, len(records))
+
+	for idx, record := range records {
+		selected[idx] = make([]string, len(indexes))
+		for pos, column := range indexes {
+			selected[idx][pos] = record[column]
+		}
+	}
+
+	return columns, selected, nil
+}
+
+func writeCSV(writer io.Writer, header []string, records [][]string) error {
+	csvWriter := csv.NewWriter(writer)
+
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	if err := csvWriter.WriteAll(records); err != nil {
+		return err
+	}
+
+	return csvWriter.Error()
+}
+
+func writeTable(writer io.Writer, header []string, records [][]string) error {
+	tabs := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+
+	if _, err := fmt.Fprintln(tabs, strings.ToUpper(strings.Join(header, "\t"))); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if _, err := fmt.Fprintln(tabs, strings.Join(record, "\t")); err != nil {
+			return err
+		}
+	}
+
+	return tabs.Flush()
+}
+
+// writeVertical writes a single record as one field per line.
+func writeVertical(writer io.Writer, header []string, record []string) error {
+	tabs := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+
+	for idx, column := range header {
+		if _, err := fmt.Fprintf(tabs, "%s\t%s\n", column, record[idx]); err != nil {
+			return err
+		}
+	}
+
+	return tabs.Flush()
+}
@@ -0,0 +1,353 @@
+package etf2l
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var ErrUnknownFormat = errors.New("Unknown competition format")
+
+// GameMode is the ruleset a map is played under, which determines the win conditions exec'd for it.
+type GameMode string
+
+const (
+	ModeFiveCP    GameMode = "5cp"
+	ModeKOTH      GameMode = "koth"
+	ModeStopwatch GameMode = "stopwatch"
+	ModeCTF       GameMode = "ctf"
+)
+
+// MapGameMode infers the game mode from the map prefix. Attack/defend cp maps cannot be told apart from 5cp by
+// name and are reported as ModeFiveCP.
+func MapGameMode(mapName string) GameMode {
+	prefix, _, _ := strings.Cut(strings.ToLower(mapName), "_")
+
+	switch prefix {
+	case "koth", "ultiduo":
+		return ModeKOTH
+	case "pl", "plr":
+		return ModeStopwatch
+	case "ctf":
+		return ModeCTF
+	default:
+		return ModeFiveCP
+	}
+}
+
+// Cvar is a single console variable set by a generated config.
+type Cvar struct {
+	Name  string
+	Value string
+}
+
+// ConfigFormat describes the server settings of a competition format.
+type ConfigFormat struct {
+	// Name is used in the generated file names, eg: etf2l_6v6.cfg.
+	Name string
+	// Whitelist is the key of the format's whitelist in the Whitelists listing.
+	Whitelist string
+	// Settings are applied by the base config of the format.
+	Settings []Cvar
+	// ClassLimits by class name as used by tf_tournament_classlimit_<class>. Classes without a limit are omitted.
+	ClassLimits map[string]int
+	// Modes holds the win conditions per game mode, each mode gets its own config which execs the base config.
+	Modes map[GameMode][]Cvar
+}
+
+var baseSettings = []Cvar{
+	{Name: "mp_tournament", Value: "1"},
+	{Name: "mp_tournament_allow_non_admin_restart", Value: "0"},
+	{Name: "mp_autoteambalance", Value: "0"},
+	{Name: "mp_teams_unbalance_limit", Value: "0"},
+	{Name: "tf_weapon_criticals", Value: "0"},
+	{Name: "tf_use_fixed_weaponspreads", Value: "1"},
+	{Name: "tf_damage_disablespread", Value: "1"},
+}
+
+func winConditions(timeLimit int, winLimit int, extra ...Cvar) []Cvar {
+	return append([]Cvar{
+		{Name: "mp_timelimit", Value: fmt.Sprint(timeLimit)},
+		{Name: "mp_winlimit", Value: fmt.Sprint(winLimit)},
+	}, extra...)
+}
+
+var stopwatch = winConditions(0, 0, Cvar{Name: "mp_tournament_stopwatch", Value: "1"})
+
+func limitAll(limit int) map[string]int {
+	limits := map[string]int{}
+	for _, class := range []string{"scout", "soldier", "pyro", "demoman", "heavy", "engineer", "medic", "sniper", "spy"} {
+		limits[class] = limit
+	}
+
+	return limits
+}
+
+// ConfigFormats are the known competition formats, keyed by ConfigFormat.Name. The values follow the ETF2L rules
+// at the time of writing and can be adjusted before generating a pack.
+var ConfigFormats = map[string]ConfigFormat{
+	"6v6": {
+		Name:      "6v6",
+		Whitelist: "6v6",
+		Settings:  baseSettings,
+		ClassLimits: map[string]int{
+			"scout": 2, "soldier": 2, "pyro": 2, "demoman": 1, "heavy": 1, "engineer": 1, "medic": 1, "sniper": 2, "spy": 2,
+		},
+		Modes: map[GameMode][]Cvar{
+			ModeFiveCP:    winConditions(30, 5, Cvar{Name: "mp_windifference", Value: "5"}),
+			ModeKOTH:      winConditions(0, 4),
+			ModeStopwatch: stopwatch,
+		},
+	},
+	"9v9": {
+		Name:        "9v9",
+		Whitelist:   "9v9",
+		Settings:    baseSettings,
+		ClassLimits: limitAll(1),
+		Modes: map[GameMode][]Cvar{
+			ModeFiveCP:    winConditions(30, 3),
+			ModeKOTH:      winConditions(0, 3),
+			ModeStopwatch: stopwatch,
+		},
+	},
+	"prolander": {
+		Name:        "prolander",
+		Whitelist:   "prolander",
+		Settings:    baseSettings,
+		ClassLimits: limitAll(1),
+		Modes: map[GameMode][]Cvar{
+			ModeFiveCP:    winConditions(30, 3),
+			ModeKOTH:      winConditions(0, 3),
+			ModeStopwatch: stopwatch,
+		},
+	},
+	"ultiduo": {
+		Name:        "ultiduo",
+		Whitelist:   "ultiduo",
+		Settings:    baseSettings,
+		ClassLimits: map[string]int{"scout": 0, "soldier": 1, "pyro": 0, "demoman": 0, "heavy": 0, "engineer": 0, "medic": 1, "sniper": 0, "spy": 0},
+		Modes: map[GameMode][]Cvar{
+			ModeKOTH: winConditions(0, 4),
+		},
+	},
+	"bball": {
+		Name:        "bball",
+		Whitelist:   "bball",
+		Settings:    baseSettings,
+		ClassLimits: map[string]int{"scout": 0, "soldier": 2, "pyro": 0, "demoman": 0, "heavy": 0, "engineer": 0, "medic": 0, "sniper": 0, "spy": 0},
+		Modes: map[GameMode][]Cvar{
+			ModeCTF: winConditions(20, 5),
+		},
+	},
+}
+
+// CompetitionConfigFormat picks the ConfigFormat of a competition from its type and category.
+func CompetitionConfigFormat(details CompetitionDetails) (ConfigFormat, error) {
+	kind := strings.ToLower(details.Type + " " + details.Category)
+
+	var name string
+
+	switch {
+	case strings.Contains(kind, "ultiduo"):
+		name = "ultiduo"
+	case strings.Contains(kind, "bball"), strings.Contains(kind, "ballin"):
+		name = "bball"
+	case strings.Contains(kind, "prolander"):
+		name = "prolander"
+	case strings.Contains(kind, "highlander"), strings.Contains(kind, "9v9"):
+		name = "9v9"
+	case strings.Contains(kind, "6on6"), strings.Contains(kind, "6v6"):
+		name = "6v6"
+	}
+
+	format, found := ConfigFormats[name]
+	if !found {
+		return ConfigFormat{}, errors.Wrapf(ErrUnknownFormat, "%s (%s)", details.Type, details.Category)
+	}
+
+	return format, nil
+}
+
+// ConfigFile is a single file of a ConfigPack, Path is relative to the tf directory of the server.
+type ConfigFile struct {
+	Path    string
+	Content []byte
+}
+
+// ConfigPack is the set of server configs needed to host the matches of a competition.
+type ConfigPack struct {
+	Competition CompetitionDetails
+	Format      ConfigFormat
+	// WhitelistFile is the file name of the whitelist, eg: etf2l_whitelist_6v6.txt.
+	WhitelistFile string
+	// Whitelist is optional, the base config does not set mp_tournament_whitelist without it.
+	Whitelist *ItemWhitelist
+}
+
+func (p ConfigPack) configName(suffix string) string {
+	name := "etf2l_" + p.Format.Name
+	if suffix != "" {
+		name += "_" + suffix
+	}
+
+	return name
+}
+
+// Modes returns the game modes of the map pool which the format has settings for, in a stable order.
+func (p ConfigPack) Modes() []GameMode {
+	var modes []GameMode
+
+	for _, mapName := range p.Competition.Pool {
+		mode := MapGameMode(mapName)
+		if _, found := p.Format.Modes[mode]; found && !slices.Contains(modes, mode) {
+			modes = append(modes, mode)
+		}
+	}
+
+	slices.Sort(modes)
+
+	return modes
+}
+
+// Files renders the pack. The output only depends on the pack contents so that regenerated packs can be diffed.
+func (p ConfigPack) Files() ([]ConfigFile, error) {
+	var (
+		files     []ConfigFile
+		header    = fmt.Sprintf("// Generated for %s (competition %d), changes will be overwritten.\n", p.Competition.Name, p.Competition.ID)
+		mapcycle  = "mapcycle_" + p.configName("") + ".txt"
+		base      strings.Builder
+		baseName  = p.configName("") + ".cfg"
+		cycleBody strings.Builder
+	)
+
+	base.WriteString(header)
+	writeCvars(&base, p.Format.Settings)
+
+	if p.Whitelist != nil {
+		if p.WhitelistFile == "" {
+			return nil, errors.New("Missing whitelist file name")
+		}
+
+		var whitelist strings.Builder
+		if _, err := p.Whitelist.WriteTo(&whitelist); err != nil {
+			return nil, errors.Wrap(err, "Failed to render whitelist")
+		}
+
+		files = append(files, ConfigFile{Path: path.Join("cfg", p.WhitelistFile), Content: []byte(whitelist.String())})
+		writeCvars(&base, []Cvar{{Name: "mp_tournament_whitelist", Value: "cfg/" + p.WhitelistFile}})
+	}
+
+	classes := make([]string, 0, len(p.Format.ClassLimits))
+	for class := range p.Format.ClassLimits {
+		classes = append(classes, class)
+	}
+
+	slices.Sort(classes)
+
+	for _, class := range classes {
+		writeCvars(&base, []Cvar{{Name: "tf_tournament_classlimit_" + class, Value: fmt.Sprint(p.Format.ClassLimits[class])}})
+	}
+
+	if len(p.Competition.Pool) > 0 {
+		writeCvars(&base, []Cvar{{Name: "mapcyclefile", Value: mapcycle}})
+
+		for _, mapName := range p.Competition.Pool {
+			cycleBody.WriteString(mapName + "\n")
+		}
+
+		files = append(files, ConfigFile{Path: path.Join("cfg", mapcycle), Content: []byte(cycleBody.String())})
+	}
+
+	files = append(files, ConfigFile{Path: path.Join("cfg", baseName), Content: []byte(base.String())})
+
+	for _, mode := range p.Modes() {
+		var modeCfg strings.Builder
+
+		modeCfg.WriteString(header)
+		modeCfg.WriteString("exec " + p.configName("") + "\n")
+		writeCvars(&modeCfg, p.Format.Modes[mode])
+
+		files = append(files, ConfigFile{Path: path.Join("cfg", p.configName(string(mode))+".cfg"), Content: []byte(modeCfg.String())})
+	}
+
+	slices.SortFunc(files, func(a, b ConfigFile) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return files, nil
+}
+
+func writeCvars(builder *strings.Builder, cvars []Cvar) {
+	for _, cvar := range cvars {
+		builder.WriteString(fmt.Sprintf("%s %q\n", cvar.Name, cvar.Value))
+	}
+}
+
+// WriteDir writes the pack files below dir, which is usually the tf directory of a server.
+func (p ConfigPack) WriteDir(dir string) error {
+	files, errFiles := p.Files()
+	if errFiles != nil {
+		return errFiles
+	}
+
+	for _, file := range files {
+		target := filepath.Join(dir, filepath.FromSlash(file.Path))
+
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return errors.Wrap(err, "Failed to create config directory")
+		}
+
+		if err := os.WriteFile(target, file.Content, 0o644); err != nil {
+			return errors.Wrapf(err, "Failed to write %s", file.Path)
+		}
+	}
+
+	return nil
+}
+
+// CompetitionConfigPack builds the config pack of a competition. Cups use the cup variant of the format whitelist
+// when one is listed. ErrNotFound is returned when the format whitelist is not listed, rather than producing a
+// pack which allows every item.
+func (client *Client) CompetitionConfigPack(ctx context.Context, httpClient HTTPExecutor, competitionID int) (*ConfigPack, error) {
+	details, errDetails := client.CompetitionDetails(ctx, httpClient, competitionID)
+	if errDetails != nil {
+		return nil, errDetails
+	}
+
+	format, errFormat := CompetitionConfigFormat(details)
+	if errFormat != nil {
+		return nil, errFormat
+	}
+
+	pack := &ConfigPack{Competition: details, Format: format}
+
+	listing, errListing := client.Whitelists(ctx, httpClient)
+	if errListing != nil {
+		return nil, errListing
+	}
+
+	whitelist, found := listing[format.Whitelist]
+	if cup, foundCup := listing[format.Whitelist+"_cup"]; foundCup && strings.Contains(strings.ToLower(details.Category), "cup") {
+		whitelist, found = cup, true
+	}
+
+	if !found {
+		return nil, errors.Wrapf(ErrNotFound, "No whitelist for %s", format.Whitelist)
+	}
+
+	items, errItems := client.ItemWhitelist(ctx, httpClient, whitelist)
+	if errItems != nil {
+		return nil, errItems
+	}
+
+	pack.Whitelist = items
+	pack.WhitelistFile = path.Base(whitelist.Filename)
+
+	return pack, nil
+}
@@ -0,0 +1,90 @@
+package demo
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/leighmacdonald/etf2l"
+)
+
+type IssueKind string
+
+const (
+	// MapMismatch demos were recorded on a map which was not played in the match.
+	MapMismatch IssueKind = "map_mismatch"
+	// UploaderMismatch first person demos were recorded by someone other than the uploader.
+	UploaderMismatch IssueKind = "uploader_mismatch"
+	// Incomplete demos have no recorded ticks, usually because the recording was not stopped cleanly.
+	Incomplete IssueKind = "incomplete"
+	// WrongGame demos were not recorded in TF2.
+	WrongGame IssueKind = "wrong_game"
+)
+
+// Issue is a single problem found when cross-checking a demo against its match.
+type Issue struct {
+	Kind   IssueKind
+	Detail string
+}
+
+// Check cross-checks a demo header against the demo listing and the match it was uploaded for.
+func Check(header Header, upload etf2l.Demo, match etf2l.MatchDetails) []Issue {
+	var issues []Issue
+
+	if header.GameDirectory != "" && !strings.EqualFold(header.GameDirectory, "tf") {
+		issues = append(issues, Issue{Kind: WrongGame, Detail: fmt.Sprintf("game directory is %q", header.GameDirectory)})
+	}
+
+	if header.Ticks <= 0 || header.Duration <= 0 {
+		issues = append(issues, Issue{Kind: Incomplete, Detail: "demo has no recorded ticks"})
+	}
+
+	if len(match.Maps) > 0 && !slices.ContainsFunc(match.Maps, func(name string) bool {
+		return strings.EqualFold(name, header.MapName)
+	}) {
+		issues = append(issues, Issue{
+			Kind:   MapMismatch,
+			Detail: fmt.Sprintf("recorded on %s, match maps are %s", header.MapName, strings.Join(match.Maps, ", ")),
+		})
+	}
+
+	// The client name of STV demos is the SourceTV name of the server, so only first person demos are checked.
+	if upload.FirstPerson && upload.OwnerName != "" && !sameName(header.ClientName, upload.OwnerName) {
+		issues = append(issues, Issue{
+			Kind:   UploaderMismatch,
+			Detail: fmt.Sprintf("recorded by %q, uploaded by %q", header.ClientName, upload.OwnerName),
+		})
+	}
+
+	return issues
+}
+
+// sameName compares player names ignoring case and a leading clan tag, in game names often carry a team tag the
+// site name does not.
+func sameName(clientName string, ownerName string) bool {
+	owner := playerName(ownerName)
+	if owner == "" {
+		return false
+	}
+
+	return playerName(clientName) == owner
+}
+
+// playerName strips a leading clan tag, eg: "[TAG] name" or "|TAG| name", and normalises the case.
+func playerName(name string) string {
+	name = strings.TrimSpace(name)
+
+	for _, delims := range []string{"[]", "||"} {
+		if !strings.HasPrefix(name, delims[:1]) {
+			continue
+		}
+
+		if end := strings.Index(name[1:], delims[1:]); end >= 0 {
+			name = strings.TrimSpace(name[end+2:])
+		}
+
+		break
+	}
+
+	return strings.ToLower(name)
+}
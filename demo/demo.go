@@ -0,0 +1,248 @@
+// Package demo reads the header of Source engine (HL2DEMO) demo files, unwrapping the zip, bzip2 and gzip
+// archives demos are commonly uploaded in, and cross-checks them against the ETF2L match they were uploaded for.
+package demo
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	magic      = "HL2DEMO\x00"
+	pathLength = 260
+	// HeaderSize is the size in bytes of the header at the start of every demo.
+	HeaderSize = 1072
+)
+
+var (
+	ErrNotDemo   = errors.New("not a HL2DEMO file")
+	ErrNoDemos   = errors.New("archive contains no demos")
+	errTruncated = errors.New("demo header is truncated")
+)
+
+// Header is the fixed size header found at the start of every demo.
+type Header struct {
+	DemoProtocol    int32
+	NetworkProtocol int32
+	ServerName      string
+	// ClientName is the player who recorded the demo, or the SourceTV name for STV demos.
+	ClientName    string
+	MapName       string
+	GameDirectory string
+	// Duration is the playback time of the demo.
+	Duration     time.Duration
+	Ticks        int32
+	Frames       int32
+	SignOnLength int32
+}
+
+// TickRate returns the number of ticks per second, 0 when the demo has no duration.
+func (h Header) TickRate() float64 {
+	if h.Duration <= 0 {
+		return 0
+	}
+
+	return float64(h.Ticks) / h.Duration.Seconds()
+}
+
+// rawHeader mirrors the on disk layout of the header.
+type rawHeader struct {
+	Magic           [8]byte
+	DemoProtocol    int32
+	NetworkProtocol int32
+	ServerName      [pathLength]byte
+	ClientName      [pathLength]byte
+	MapName         [pathLength]byte
+	GameDirectory   [pathLength]byte
+	PlaybackTime    float32
+	Ticks           int32
+	Frames          int32
+	SignOnLength    int32
+}
+
+func cString(value []byte) string {
+	if idx := bytes.IndexByte(value, 0); idx >= 0 {
+		value = value[:idx]
+	}
+
+	return string(value)
+}
+
+// ReadHeader reads the header of an uncompressed demo.
+func ReadHeader(reader io.Reader) (Header, error) {
+	var raw rawHeader
+
+	if err := binary.Read(reader, binary.LittleEndian, &raw); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return Header{}, errors.Join(err, errTruncated)
+		}
+
+		return Header{}, err
+	}
+
+	if string(raw.Magic[:]) != magic {
+		return Header{}, ErrNotDemo
+	}
+
+	playback := float64(raw.PlaybackTime)
+	if math.IsNaN(playback) || math.IsInf(playback, 0) || playback < 0 {
+		playback = 0
+	}
+
+	return Header{
+		DemoProtocol:    raw.DemoProtocol,
+		NetworkProtocol: raw.NetworkProtocol,
+		ServerName:      cString(raw.ServerName[:]),
+		ClientName:      cString(raw.ClientName[:]),
+		MapName:         cString(raw.MapName[:]),
+		GameDirectory:   cString(raw.GameDirectory[:]),
+		Duration:        time.Duration(playback * float64(time.Second)),
+		Ticks:           raw.Ticks,
+		Frames:          raw.Frames,
+		SignOnLength:    raw.SignOnLength,
+	}, nil
+}
+
+// File is a single demo found within a file or archive.
+type File struct {
+	// Name of the demo, for archives this is the name of the entry.
+	Name   string
+	Header Header
+}
+
+// ParseFile reads the headers of the demos in a file, which may be a plain demo or a zip, bzip2 or gzip archive.
+// Zip archives can contain multiple demos, entries which are not demos are ignored.
+func ParseFile(path string) ([]File, error) {
+	file, errOpen := os.Open(path)
+	if errOpen != nil {
+		return nil, errOpen
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	info, errStat := file.Stat()
+	if errStat != nil {
+		return nil, errStat
+	}
+
+	return parse(filepath.Base(path), file, file, info.Size())
+}
+
+// Parse reads the headers of the demos read from reader, see ParseFile. Zip archives are buffered in memory.
+func Parse(name string, reader io.Reader) ([]File, error) {
+	return parse(name, reader, nil, 0)
+}
+
+func parse(name string, reader io.Reader, readerAt io.ReaderAt, size int64) ([]File, error) {
+	buffered := bufio.NewReader(reader)
+
+	signature, errPeek := buffered.Peek(4)
+	if errPeek != nil && !errors.Is(errPeek, io.EOF) {
+		return nil, errPeek
+	}
+
+	switch {
+	case bytes.HasPrefix(signature, []byte("PK\x03\x04")):
+		if readerAt == nil {
+			body, errRead := io.ReadAll(buffered)
+			if errRead != nil {
+				return nil, errRead
+			}
+
+			readerAt, size = bytes.NewReader(body), int64(len(body))
+		}
+
+		return parseZip(readerAt, size)
+	case bytes.HasPrefix(signature, []byte("BZh")):
+		return parseSingle(trimExtension(name, ".bz2"), bzip2.NewReader(buffered))
+	case bytes.HasPrefix(signature, []byte{0x1f, 0x8b}):
+		gzipReader, errGzip := gzip.NewReader(buffered)
+		if errGzip != nil {
+			return nil, errGzip
+		}
+
+		defer func() {
+			_ = gzipReader.Close()
+		}()
+
+		return parseSingle(trimExtension(name, ".gz"), gzipReader)
+	default:
+		return parseSingle(name, buffered)
+	}
+}
+
+func trimExtension(name string, extension string) string {
+	if strings.HasSuffix(strings.ToLower(name), extension) {
+		return name[:len(name)-len(extension)]
+	}
+
+	return name
+}
+
+func parseSingle(name string, reader io.Reader) ([]File, error) {
+	header, err := ReadHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return []File{{Name: name, Header: header}}, nil
+}
+
+func parseZip(readerAt io.ReaderAt, size int64) ([]File, error) {
+	archive, errZip := zip.NewReader(readerAt, size)
+	if errZip != nil {
+		return nil, errZip
+	}
+
+	var files []File
+
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+
+		header, errHeader := readEntry(entry)
+		if errHeader != nil {
+			if errors.Is(errHeader, ErrNotDemo) || errors.Is(errHeader, errTruncated) {
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name, errHeader)
+		}
+
+		files = append(files, File{Name: entry.Name, Header: header})
+	}
+
+	if len(files) == 0 {
+		return nil, ErrNoDemos
+	}
+
+	return files, nil
+}
+
+func readEntry(entry *zip.File) (Header, error) {
+	reader, errOpen := entry.Open()
+	if errOpen != nil {
+		return Header{}, errOpen
+	}
+
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	return ReadHeader(reader)
+}
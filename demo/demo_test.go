@@ -0,0 +1,156 @@
+package demo_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/etf2l"
+	"github.com/leighmacdonald/etf2l/demo"
+	"github.com/stretchr/testify/require"
+)
+
+func header(t *testing.T, client string, mapName string) []byte {
+	t.Helper()
+
+	field := func(value string) []byte {
+		out := make([]byte, 260)
+		copy(out, value)
+
+		return out
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString("HL2DEMO\x00")
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, []int32{3, 24}))
+
+	for _, value := range []string{"ETF2L #1", client, mapName, "tf"} {
+		buf.Write(field(value))
+	}
+
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, float32(1800)))
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, []int32{119400, 58000, 400000}))
+	require.Equal(t, demo.HeaderSize, buf.Len())
+
+	return buf.Bytes()
+}
+
+func TestParse(t *testing.T) {
+	raw := header(t, "player", "koth_product_final")
+
+	files, err := demo.Parse("pov.dem", bytes.NewReader(raw))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	parsed := files[0].Header
+	require.Equal(t, "pov.dem", files[0].Name)
+	require.Equal(t, int32(3), parsed.DemoProtocol)
+	require.Equal(t, int32(24), parsed.NetworkProtocol)
+	require.Equal(t, "ETF2L #1", parsed.ServerName)
+	require.Equal(t, "player", parsed.ClientName)
+	require.Equal(t, "koth_product_final", parsed.MapName)
+	require.Equal(t, "tf", parsed.GameDirectory)
+	require.Equal(t, 30*time.Minute, parsed.Duration)
+	require.Equal(t, int32(119400), parsed.Ticks)
+	require.InDelta(t, 66.33, parsed.TickRate(), 0.01)
+
+	var gzipped bytes.Buffer
+
+	gzipWriter := gzip.NewWriter(&gzipped)
+	_, _ = gzipWriter.Write(raw)
+	require.NoError(t, gzipWriter.Close())
+
+	files, err = demo.Parse("pov.dem.gz", &gzipped)
+	require.NoError(t, err)
+	require.Equal(t, "pov.dem", files[0].Name)
+	require.Equal(t, parsed, files[0].Header)
+
+	var zipped bytes.Buffer
+
+	zipWriter := zip.NewWriter(&zipped)
+
+	for name, body := range map[string][]byte{
+		"readme.txt": []byte("gg"),
+		"map1.dem":   raw,
+		"map2.dem":   header(t, "player", "cp_process_final"),
+	} {
+		entry, errCreate := zipWriter.Create(name)
+		require.NoError(t, errCreate)
+
+		_, _ = entry.Write(body)
+	}
+
+	require.NoError(t, zipWriter.Close())
+
+	zipPath := filepath.Join(t.TempDir(), "demos.zip")
+	require.NoError(t, os.WriteFile(zipPath, zipped.Bytes(), 0o600))
+
+	files, err = demo.ParseFile(zipPath)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	maps := []string{files[0].Header.MapName, files[1].Header.MapName}
+	require.ElementsMatch(t, []string{"koth_product_final", "cp_process_final"}, maps)
+
+	files, err = demo.ParseFile(filepath.Join("testdata", "stv.dem.bz2"))
+	require.NoError(t, err)
+	require.Equal(t, "stv.dem", files[0].Name)
+	require.Equal(t, "SourceTV", files[0].Header.ClientName)
+	require.Equal(t, "cp_process_final", files[0].Header.MapName)
+
+	_, errNotDemo := demo.Parse("notes.txt", bytes.NewReader(bytes.Repeat([]byte("x"), demo.HeaderSize)))
+	require.ErrorIs(t, errNotDemo, demo.ErrNotDemo)
+
+	_, errShort := demo.Parse("short.dem", bytes.NewReader(raw[:100]))
+	require.Error(t, errShort)
+}
+
+func TestCheck(t *testing.T) {
+	files, err := demo.Parse("pov.dem", bytes.NewReader(header(t, "[TAG] player", "cp_gullywash_final1")))
+	require.NoError(t, err)
+
+	parsed := files[0].Header
+	match := etf2l.MatchDetails{Maps: []string{"cp_process_final", "koth_product_final"}}
+
+	issues := demo.Check(parsed, etf2l.Demo{FirstPerson: true, OwnerName: "Player"}, match)
+	require.Len(t, issues, 1)
+	require.Equal(t, demo.MapMismatch, issues[0].Kind)
+
+	issues = demo.Check(parsed, etf2l.Demo{FirstPerson: true, OwnerName: "someone"}, etf2l.MatchDetails{})
+	require.Len(t, issues, 1)
+	require.Equal(t, demo.UploaderMismatch, issues[0].Kind)
+
+	require.Empty(t, demo.Check(parsed, etf2l.Demo{Stv: true, OwnerName: "someone"}, etf2l.MatchDetails{}))
+
+	for owner, matches := range map[string]bool{
+		"player":      true,
+		"[TAG]player": true,
+		"play":        false,
+		"player2":     false,
+		"[TAG]":       false,
+		" ":           false,
+	} {
+		issues = demo.Check(parsed, etf2l.Demo{FirstPerson: true, OwnerName: owner}, etf2l.MatchDetails{})
+		require.Equal(t, matches, len(issues) == 0, owner)
+	}
+
+	piped, errPiped := demo.Parse("pov.dem", bytes.NewReader(header(t, "|TAG| Player", "cp_process_final")))
+	require.NoError(t, errPiped)
+	require.Empty(t, demo.Check(piped[0].Header, etf2l.Demo{FirstPerson: true, OwnerName: "player"}, etf2l.MatchDetails{}))
+
+	parsed.Ticks = 0
+	parsed.GameDirectory = "cstrike"
+
+	kinds := []demo.IssueKind{}
+	for _, issue := range demo.Check(parsed, etf2l.Demo{}, etf2l.MatchDetails{}) {
+		kinds = append(kinds, issue.Kind)
+	}
+
+	require.Equal(t, []demo.IssueKind{demo.WrongGame, demo.Incomplete}, kinds)
+}
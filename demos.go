@@ -57,12 +57,12 @@ func (resp demosResponse) NextURL(r Recursive) (string, error) {
 }
 
 type DemoOpts struct {
-	Recursive
-	PlayerID string   `url:"player,omitempty"`
-	Type     []string `url:"type,omitempty"` // stv, first_person
-	Pruned   bool     `url:"pruned,omitempty"`
-	From     int      `url:"from,omitempty"` // unixtime start
-	To       int      `url:"to,omitempty"`   // unixtime end
+	Recursive `url:"-"`
+	PlayerID  string   `url:"player,omitempty"`
+	Type      []string `url:"type,omitempty"` // stv, first_person
+	Pruned    bool     `url:"pruned,omitempty"`
+	From      int      `url:"from,omitempty"` // unixtime start
+	To        int      `url:"to,omitempty"`   // unixtime end
 }
 
 func getPath(path string) (string, error) {
@@ -77,11 +77,14 @@ func getPath(path string) (string, error) {
 func (client *Client) Demos(ctx context.Context, httpClient HTTPExecutor, opts Recursive) ([]Demo, error) {
 	var demos []Demo
 
-	curPath := "/demos"
+	curPath, errPath := queryPath("/demos", opts)
+	if errPath != nil {
+		return nil, errPath
+	}
 
 	for {
 		var resp demosResponse
-		if err := client.call(ctx, httpClient, curPath, opts, &resp); err != nil {
+		if err := client.call(ctx, httpClient, curPath, nil, &resp); err != nil {
 			return nil, err
 		}
 
@@ -96,7 +99,10 @@ func (client *Client) Demos(ctx context.Context, httpClient HTTPExecutor, opts R
 			return nil, err
 		}
 
-		curPath = nextURL
+		curPath, errPath = pagePath(nextURL, opts)
+		if errPath != nil {
+			return nil, errPath
+		}
 	}
 
 	return demos, nil
@@ -0,0 +1,454 @@
+package etf2l
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDemoLayout stores demos under the competition and match they belong to.
+	DefaultDemoLayout      = "{competition}/{match}/{file}"
+	DefaultDemoConcurrency = 2
+	// DefaultDemoInterval is the minimum time between starting two downloads.
+	DefaultDemoInterval = 500 * time.Millisecond
+	partialSuffix       = ".part"
+)
+
+var (
+	ErrDemoSize = errors.New("Downloaded demo size does not match")
+	errDemoURL  = errors.New("Demo has no download url")
+)
+
+// DemoResult is the outcome of downloading a single demo.
+type DemoResult struct {
+	Demo Demo
+	Path string
+	// Size of the complete file in bytes.
+	Size int64
+	// SHA256 is the hex encoded checksum of the complete file.
+	SHA256 string
+	// Skipped is set for pruned demos and demos which were already downloaded.
+	Skipped bool
+	// Resumed is set when a partial download was continued.
+	Resumed bool
+	Err     error
+}
+
+// DemoDownloader downloads demo files into a directory. Downloads are first written to a ".part" file, which is
+// resumed with a Range request on the next attempt, and only moved into place once the size has been verified.
+type DemoDownloader struct {
+	dir         string
+	httpClient  HTTPExecutor
+	layout      string
+	concurrency int
+	interval    time.Duration
+}
+
+type DemoDownloaderOption func(*DemoDownloader)
+
+// WithDemoLayout sets the path of each demo relative to the download directory. The placeholders {competition},
+// {competition_id}, {match}, {id}, {owner}, {type} and {file} are replaced with the values of each demo.
+func WithDemoLayout(layout string) DemoDownloaderOption {
+	return func(downloader *DemoDownloader) {
+		downloader.layout = layout
+	}
+}
+
+// WithDemoConcurrency sets how many demos are downloaded at the same time.
+func WithDemoConcurrency(concurrency int) DemoDownloaderOption {
+	return func(downloader *DemoDownloader) {
+		downloader.concurrency = max(concurrency, 1)
+	}
+}
+
+// WithDemoInterval sets the minimum time between starting two downloads, 0 disables the limit.
+func WithDemoInterval(interval time.Duration) DemoDownloaderOption {
+	return func(downloader *DemoDownloader) {
+		downloader.interval = interval
+	}
+}
+
+func NewDemoDownloader(dir string, httpClient HTTPExecutor, opts ...DemoDownloaderOption) *DemoDownloader {
+	downloader := &DemoDownloader{
+		dir:         dir,
+		httpClient:  httpClient,
+		layout:      DefaultDemoLayout,
+		concurrency: DefaultDemoConcurrency,
+		interval:    DefaultDemoInterval,
+	}
+
+	for _, opt := range opts {
+		opt(downloader)
+	}
+
+	return downloader
+}
+
+// demoJob is a demo along with the competition it was played in, which is only known for demos of match details.
+type demoJob struct {
+	demo        Demo
+	competition MatchCompetition
+}
+
+// Download downloads demos as returned by Demos. The competition of these demos is unknown so the {competition}
+// placeholder is rendered as "unknown". The returned error joins the errors of all failed downloads.
+func (d *DemoDownloader) Download(ctx context.Context, demos []Demo) ([]DemoResult, error) {
+	jobs := make([]demoJob, len(demos))
+	for idx, demo := range demos {
+		jobs[idx] = demoJob{demo: demo}
+	}
+
+	return d.run(ctx, jobs)
+}
+
+// DownloadMatches downloads the demos attached to each match.
+func (d *DemoDownloader) DownloadMatches(ctx context.Context, matches []MatchDetails) ([]DemoResult, error) {
+	var jobs []demoJob
+
+	for _, match := range matches {
+		for _, demo := range match.Demos {
+			if demo.Match == 0 {
+				demo.Match = match.ID
+			}
+
+			jobs = append(jobs, demoJob{demo: demo, competition: match.Competition})
+		}
+	}
+
+	return d.run(ctx, jobs)
+}
+
+func (d *DemoDownloader) run(ctx context.Context, jobs []demoJob) ([]DemoResult, error) {
+	var (
+		results   = make([]DemoResult, len(jobs))
+		waitGroup sync.WaitGroup
+		queue     = make(chan int)
+	)
+
+	for range min(d.concurrency, len(jobs)) {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			for idx := range queue {
+				results[idx] = d.download(ctx, jobs[idx])
+			}
+		}()
+	}
+
+	var ticker *time.Ticker
+	if d.interval > 0 {
+		ticker = time.NewTicker(d.interval)
+		defer ticker.Stop()
+	}
+
+	started := 0
+
+	for idx, job := range jobs {
+		if ctx.Err() != nil {
+			results[idx] = DemoResult{Demo: job.demo, Err: ctx.Err()}
+
+			continue
+		}
+
+		// Only actual downloads count towards the rate limit.
+		if ticker != nil && !job.demo.Pruned && started > 0 {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				results[idx] = DemoResult{Demo: job.demo, Err: ctx.Err()}
+
+				continue
+			}
+		}
+
+		if !job.demo.Pruned {
+			started++
+		}
+
+		queue <- idx
+	}
+
+	close(queue)
+	waitGroup.Wait()
+
+	var errs []error
+
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("Failed to download demo %d: %w", result.Demo.ID, result.Err))
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// DemoPath returns the path a demo is written to, relative to the download directory.
+func (d *DemoDownloader) DemoPath(demo Demo, competition MatchCompetition) string {
+	competitionName := competition.Name
+	if competitionName == "" {
+		competitionName = "unknown"
+	}
+
+	demoType := "stv"
+	if demo.FirstPerson {
+		demoType = "first_person"
+	}
+
+	replacer := strings.NewReplacer(
+		"{competition}", sanitizeSegment(competitionName),
+		"{competition_id}", strconv.Itoa(competition.ID),
+		"{match}", strconv.Itoa(demo.Match),
+		"{id}", strconv.Itoa(demo.ID),
+		"{owner}", sanitizeSegment(demo.OwnerName),
+		"{type}", demoType,
+		"{file}", sanitizeSegment(demoFileName(demo)),
+	)
+
+	return filepath.FromSlash(path.Clean(replacer.Replace(d.layout)))
+}
+
+func demoFileName(demo Demo) string {
+	name := demo.File
+	if name == "" {
+		if parsed, err := url.Parse(demo.DownloadURL); err == nil {
+			name = path.Base(parsed.Path)
+		}
+	}
+
+	if name == "" || name == "." || name == "/" {
+		name = strconv.Itoa(demo.ID)
+	}
+
+	if demo.Extension != "" && path.Ext(name) == "" {
+		name += "." + strings.TrimPrefix(demo.Extension, ".")
+	}
+
+	return name
+}
+
+// sanitizeSegment makes a value safe to use as a single path segment.
+func sanitizeSegment(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`<>:"/\|?*`, r) || r < ' ' {
+			return '_'
+		}
+
+		return r
+	}, value)
+
+	value = strings.Trim(value, " .")
+	if value == "" {
+		return "unknown"
+	}
+
+	return value
+}
+
+func (d *DemoDownloader) download(ctx context.Context, job demoJob) DemoResult {
+	result := DemoResult{Demo: job.demo, Path: filepath.Join(d.dir, d.DemoPath(job.demo, job.competition))}
+
+	switch {
+	case job.demo.Pruned:
+		result.Skipped = true
+
+		return result
+	case job.demo.DownloadURL == "":
+		result.Err = errDemoURL
+
+		return result
+	}
+
+	if _, err := os.Stat(result.Path); err == nil {
+		result.Skipped = true
+		result.Size, result.SHA256, result.Err = checksum(result.Path)
+
+		return result
+	}
+
+	if err := os.MkdirAll(filepath.Dir(result.Path), 0o755); err != nil {
+		result.Err = fmt.Errorf("Failed to create demo directory: %w", err)
+
+		return result
+	}
+
+	resumed, errFetch := d.fetch(ctx, job.demo.DownloadURL, result.Path+partialSuffix)
+	if errFetch != nil {
+		result.Err = errFetch
+
+		return result
+	}
+
+	if err := os.Rename(result.Path+partialSuffix, result.Path); err != nil {
+		result.Err = fmt.Errorf("Failed to move demo into place: %w", err)
+
+		return result
+	}
+
+	result.Resumed = resumed
+	result.Size, result.SHA256, result.Err = checksum(result.Path)
+
+	return result
+}
+
+// fetch downloads the url into the partial file, resuming from its current size. It returns whether the
+// download was resumed.
+func (d *DemoDownloader) fetch(ctx context.Context, downloadURL string, partial string) (bool, error) {
+	var offset int64
+	if info, err := os.Stat(partial); err == nil {
+		offset = info.Size()
+	}
+
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
+	if errReq != nil {
+		return false, fmt.Errorf("Failed to create request: %w", errReq)
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, errResp := d.httpClient.Do(req)
+	if errResp != nil {
+		return false, fmt.Errorf("Failed to download demo: %w", errResp)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	var (
+		flags    = os.O_CREATE | os.O_WRONLY
+		expected int64
+		resumed  bool
+	)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// The server ignored the range, start over.
+		flags |= os.O_TRUNC
+		offset = 0
+		expected = resp.ContentLength
+	case http.StatusPartialContent:
+		start, total, errRange := parseContentRange(resp.Header.Get("Content-Range"))
+		if errRange != nil {
+			return false, errRange
+		}
+
+		if start != offset {
+			return false, fmt.Errorf("Server resumed from byte %d instead of %d", start, offset)
+		}
+
+		flags |= os.O_APPEND
+		expected = total
+		resumed = true
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is already complete, or larger than the demo in which case it is discarded.
+		_, total, errRange := parseContentRange(resp.Header.Get("Content-Range"))
+		if errRange == nil && total == offset {
+			return true, nil
+		}
+
+		_ = os.Remove(partial)
+
+		return false, fmt.Errorf("Partial download is larger than the demo: %w", ErrDemoSize)
+	case http.StatusNotFound:
+		return false, ErrNotFound
+	default:
+		return false, fmt.Errorf("Invalid status code: %s", resp.Status)
+	}
+
+	file, errOpen := os.OpenFile(partial, flags, 0o644)
+	if errOpen != nil {
+		return false, fmt.Errorf("Failed to open partial demo: %w", errOpen)
+	}
+
+	written, errCopy := io.Copy(file, resp.Body)
+	errClose := file.Close()
+
+	if errCopy != nil {
+		return false, fmt.Errorf("Failed to write demo: %w", errCopy)
+	}
+
+	if errClose != nil {
+		return false, fmt.Errorf("Failed to close demo: %w", errClose)
+	}
+
+	if expected >= 0 && offset+written != expected {
+		return false, fmt.Errorf("Expected %d bytes, got %d: %w", expected, offset+written, ErrDemoSize)
+	}
+
+	return resumed, nil
+}
+
+// parseContentRange parses a Content-Range header such as "bytes 100-199/200" or "bytes */200". The total is
+// -1 when unknown.
+func parseContentRange(header string) (int64, int64, error) {
+	spec, found := strings.CutPrefix(header, "bytes ")
+	if !found {
+		return 0, 0, fmt.Errorf("Invalid Content-Range: %q", header)
+	}
+
+	rangeSpec, totalSpec, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, fmt.Errorf("Invalid Content-Range: %q", header)
+	}
+
+	total := int64(-1)
+
+	if totalSpec != "*" {
+		parsed, err := strconv.ParseInt(totalSpec, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Invalid Content-Range: %q: %w", header, err)
+		}
+
+		total = parsed
+	}
+
+	if rangeSpec == "*" {
+		return 0, total, nil
+	}
+
+	startSpec, _, _ := strings.Cut(rangeSpec, "-")
+
+	start, err := strconv.ParseInt(startSpec, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Invalid Content-Range: %q: %w", header, err)
+	}
+
+	return start, total, nil
+}
+
+func checksum(filePath string) (int64, string, error) {
+	file, errOpen := os.Open(filePath)
+	if errOpen != nil {
+		return 0, "", fmt.Errorf("Failed to open demo: %w", errOpen)
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	hash := sha256.New()
+
+	size, errCopy := io.Copy(hash, file)
+	if errCopy != nil {
+		return 0, "", fmt.Errorf("Failed to read demo: %w", errCopy)
+	}
+
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
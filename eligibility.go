@@ -0,0 +1,246 @@
+package etf2l
+
+import (
+	"context"
+	"time"
+)
+
+type EligibilityIssueKind string
+
+const (
+	// NotRostered players were not on the roster of the team they played for, eg: mercs.
+	NotRostered EligibilityIssueKind = "not_rostered"
+	// LateJoin players were not rostered at the time of the match but joined the team afterwards.
+	LateJoin EligibilityIssueKind = "late_join"
+	// Banned players had an active ban at the time of the match.
+	Banned EligibilityIssueKind = "banned"
+)
+
+// EligibilityIssue describes why a single player was not eligible to play in a match.
+type EligibilityIssue struct {
+	Player MatchPlayer
+	// TeamID is the team the player played for, or 0 when it could not be determined.
+	TeamID int
+	Kind   EligibilityIssueKind
+	// Ban is the ban that was active at the time of the match, set for Banned issues.
+	Ban *Ban
+	// JoinedAt is when the player eventually joined the team, set for LateJoin issues.
+	JoinedAt time.Time
+}
+
+// MatchEligibility is the eligibility report for a single match.
+type MatchEligibility struct {
+	MatchID int
+	Clan1   MatchClan
+	Clan2   MatchClan
+	Time    time.Time
+	Issues  []EligibilityIssue
+}
+
+// Eligible reports whether every player in the match was eligible.
+func (r MatchEligibility) Eligible() bool {
+	return len(r.Issues) == 0
+}
+
+// CompetitionEligibility is the eligibility report for all matches of a competition.
+type CompetitionEligibility struct {
+	CompetitionID int
+	Matches       []MatchEligibility
+}
+
+// Flagged returns the reports of matches which had at least one ineligible player.
+func (r CompetitionEligibility) Flagged() []MatchEligibility {
+	var flagged []MatchEligibility
+
+	for _, match := range r.Matches {
+		if !match.Eligible() {
+			flagged = append(flagged, match)
+		}
+	}
+
+	return flagged
+}
+
+// EligibilityChecker flags players of a match who were not rostered or were banned at the time the match was
+// played.
+type EligibilityChecker struct {
+	rosters map[int]RosterHistory
+	bans    map[int64][]Ban
+}
+
+// NewEligibilityChecker creates a checker using the roster histories of the teams involved and the list of bans,
+// bans are matched to players using their steam id.
+func NewEligibilityChecker(rosters []RosterHistory, bans []Ban) *EligibilityChecker {
+	checker := &EligibilityChecker{
+		rosters: map[int]RosterHistory{},
+		bans:    map[int64][]Ban{},
+	}
+
+	for _, roster := range rosters {
+		checker.rosters[roster.TeamID] = roster
+	}
+
+	for _, ban := range bans {
+		checker.bans[ban.Steamid64.Int64()] = append(checker.bans[ban.Steamid64.Int64()], ban)
+	}
+
+	return checker
+}
+
+// activeBan returns the ban, if any, which was in effect for the player at the given time.
+func (c *EligibilityChecker) activeBan(player MatchPlayer, at time.Time) *Ban {
+	for _, ban := range c.bans[player.Steam.ID64.Int64()] {
+		if int64(ban.Start) <= at.Unix() && (ban.End == 0 || at.Unix() < int64(ban.End)) {
+			return &ban
+		}
+	}
+
+	return nil
+}
+
+// CheckMatch returns the eligibility report for a match. Players are checked against the roster of the team
+// they played for, players without a known team are only flagged when they were rostered on neither team.
+func (c *EligibilityChecker) CheckMatch(match MatchDetails) MatchEligibility {
+	report := MatchEligibility{
+		MatchID: match.ID,
+		Clan1:   match.Clan1,
+		Clan2:   match.Clan2,
+		Time:    time.Unix(int64(match.Time), 0),
+	}
+
+	for _, player := range match.Players {
+		if ban := c.activeBan(player, report.Time); ban != nil {
+			report.Issues = append(report.Issues, EligibilityIssue{
+				Player: player,
+				TeamID: player.Team,
+				Kind:   Banned,
+				Ban:    ban,
+			})
+		}
+
+		teamIDs := []int{player.Team}
+		if player.Team == 0 {
+			teamIDs = []int{match.Clan1.ID, match.Clan2.ID}
+		}
+
+		if issue, found := c.checkRoster(player, teamIDs, report.Time); found {
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+
+	return report
+}
+
+func (c *EligibilityChecker) checkRoster(player MatchPlayer, teamIDs []int, at time.Time) (EligibilityIssue, bool) {
+	var (
+		issue   = EligibilityIssue{Player: player, TeamID: player.Team, Kind: NotRostered}
+		checked bool
+	)
+
+	for _, teamID := range teamIDs {
+		roster, found := c.rosters[teamID]
+		if !found {
+			continue
+		}
+
+		checked = true
+
+		if roster.Contains(player.ID, at) {
+			return EligibilityIssue{}, false
+		}
+
+		if joined, didJoin := roster.JoinedAfter(player.ID, at); didJoin {
+			issue.Kind = LateJoin
+			issue.TeamID = teamID
+			issue.JoinedAt = joined
+		}
+	}
+
+	// Without a roster history for any of the teams there is nothing to check against.
+	return issue, checked
+}
+
+// CheckCompetition returns the eligibility report for every provided match of a competition.
+func (c *EligibilityChecker) CheckCompetition(competitionID int, matches []MatchDetails) CompetitionEligibility {
+	report := CompetitionEligibility{CompetitionID: competitionID}
+
+	for _, match := range matches {
+		report.Matches = append(report.Matches, c.CheckMatch(match))
+	}
+
+	return report
+}
+
+// rosterCache fetches each team roster history at most once.
+type rosterCache map[int]RosterHistory
+
+func (cache rosterCache) load(ctx context.Context, client *Client, httpClient HTTPExecutor, teamIDs ...int) error {
+	for _, teamID := range teamIDs {
+		if _, found := cache[teamID]; found || teamID == 0 {
+			continue
+		}
+
+		history, err := client.TeamRosterHistory(ctx, httpClient, teamID)
+		if err != nil {
+			return err
+		}
+
+		cache[teamID] = history
+	}
+
+	return nil
+}
+
+func (cache rosterCache) histories() []RosterHistory {
+	histories := make([]RosterHistory, 0, len(cache))
+	for _, history := range cache {
+		histories = append(histories, history)
+	}
+
+	return histories
+}
+
+// CheckMatchEligibility fetches a match along with the roster history of both teams and checks it. Since the api
+// cannot filter bans by steam id, the caller provides the bans to check against, eg: from a recursive Bans call.
+func (client *Client) CheckMatchEligibility(ctx context.Context, httpClient HTTPExecutor, matchID int, bans []Ban) (MatchEligibility, error) {
+	match, errMatch := client.MatchDetails(ctx, httpClient, matchID)
+	if errMatch != nil {
+		return MatchEligibility{}, errMatch
+	}
+
+	cache := rosterCache{}
+	if err := cache.load(ctx, client, httpClient, match.Clan1.ID, match.Clan2.ID); err != nil {
+		return MatchEligibility{}, err
+	}
+
+	return NewEligibilityChecker(cache.histories(), bans).CheckMatch(*match), nil
+}
+
+// CheckCompetitionEligibility checks every match of a competition, fetching the details of each match and the
+// roster history of every participating team.
+func (client *Client) CheckCompetitionEligibility(ctx context.Context, httpClient HTTPExecutor, competitionID int, bans []Ban) (CompetitionEligibility, error) {
+	competitionMatches, errMatches := client.CompetitionMatches(ctx, httpClient, competitionID, BaseOpts{Recursive: true})
+	if errMatches != nil {
+		return CompetitionEligibility{}, errMatches
+	}
+
+	var (
+		cache   = rosterCache{}
+		matches = make([]MatchDetails, 0, len(competitionMatches))
+	)
+
+	for _, competitionMatch := range competitionMatches {
+		match, errMatch := client.MatchDetails(ctx, httpClient, competitionMatch.ID)
+		if errMatch != nil {
+			return CompetitionEligibility{}, errMatch
+		}
+
+		if err := cache.load(ctx, client, httpClient, match.Clan1.ID, match.Clan2.ID); err != nil {
+			return CompetitionEligibility{}, err
+		}
+
+		matches = append(matches, *match)
+	}
+
+	return NewEligibilityChecker(cache.histories(), bans).CheckCompetition(competitionID, matches), nil
+}
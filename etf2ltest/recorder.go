@@ -0,0 +1,227 @@
+// Package etf2ltest provides utilities for testing code built on top of the etf2l client without depending on
+// the live ETF2L api.
+package etf2ltest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/leighmacdonald/etf2l"
+)
+
+var (
+	ErrFixtureNotFound = errors.New("no fixture recorded for request")
+	errFixtureRead     = errors.New("failed to read fixture")
+	errFixtureWrite    = errors.New("failed to write fixture")
+)
+
+// Fixture is the on-disk representation of a single recorded response. URL is only set for responses recorded
+// from a live api.
+type Fixture struct {
+	Method     string          `json:"method"`
+	URL        string          `json:"url,omitempty"`
+	StatusCode int             `json:"status_code"`
+	Body       json.RawMessage `json:"body"`
+}
+
+var unsafeChars = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// FixtureName returns the file name used to store the response for a request. The name is derived from the
+// request path and query. Requests carrying a body, such as the option structs sent to /bans, additionally have
+// a short hash of the body appended so that different filters on the same path do not collide.
+func FixtureName(req *http.Request) (string, error) {
+	name := strings.Trim(unsafeChars.ReplaceAllString(req.URL.RequestURI(), "_"), "_")
+	if name == "" {
+		name = "root"
+	}
+
+	body, err := requestBody(req)
+	if err != nil {
+		return "", err
+	}
+
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		name += "_" + hex.EncodeToString(sum[:])[:8]
+	}
+
+	return strings.ToLower(name) + ".json", nil
+}
+
+// requestBody reads the request body without consuming it.
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody != nil {
+		reader, err := req.GetBody()
+		if err != nil {
+			return nil, errors.Join(err, errFixtureRead)
+		}
+
+		defer func() {
+			_ = reader.Close()
+		}()
+
+		body, errRead := io.ReadAll(reader)
+		if errRead != nil {
+			return nil, errors.Join(errRead, errFixtureRead)
+		}
+
+		return body, nil
+	}
+
+	body, errRead := io.ReadAll(req.Body)
+	if errRead != nil {
+		return nil, errors.Join(errRead, errFixtureRead)
+	}
+
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// Recorder is an etf2l.HTTPExecutor which performs each request using the wrapped executor and stores the
+// response as a golden file under Dir so that it can later be served by a Replayer.
+type Recorder struct {
+	Dir  string
+	Next etf2l.HTTPExecutor
+	mu   sync.Mutex
+}
+
+func NewRecorder(dir string, next etf2l.HTTPExecutor) *Recorder {
+	return &Recorder{Dir: dir, Next: next}
+}
+
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	name, errName := FixtureName(req)
+	if errName != nil {
+		return nil, errName
+	}
+
+	resp, errResp := r.Next.Do(req)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, errRead := io.ReadAll(resp.Body)
+	if errRead != nil {
+		return nil, errors.Join(errRead, errFixtureRead)
+	}
+
+	fixture := Fixture{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Body:       body,
+	}
+
+	// Non JSON bodies, such as html error pages, are stored as a JSON string instead.
+	if !json.Valid(body) {
+		encoded, errEncode := json.Marshal(string(body))
+		if errEncode != nil {
+			return nil, errors.Join(errEncode, errFixtureWrite)
+		}
+
+		fixture.Body = encoded
+	}
+
+	if err := r.write(name, fixture); err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return resp, nil
+}
+
+func (r *Recorder) write(name string, fixture Fixture) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+		return errors.Join(err, errFixtureWrite)
+	}
+
+	var out bytes.Buffer
+
+	encoder := json.NewEncoder(&out)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(fixture); err != nil {
+		return errors.Join(err, errFixtureWrite)
+	}
+
+	if err := os.WriteFile(filepath.Join(r.Dir, name), out.Bytes(), 0o600); err != nil {
+		return errors.Join(err, errFixtureWrite)
+	}
+
+	return nil
+}
+
+// Replayer is an etf2l.HTTPExecutor which serves responses previously stored by a Recorder. Requests without a
+// matching fixture fail with ErrFixtureNotFound.
+type Replayer struct {
+	Dir string
+}
+
+func NewReplayer(dir string) *Replayer {
+	return &Replayer{Dir: dir}
+}
+
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	name, errName := FixtureName(req)
+	if errName != nil {
+		return nil, errName
+	}
+
+	raw, errRead := os.ReadFile(filepath.Join(r.Dir, name))
+	if errRead != nil {
+		if errors.Is(errRead, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s %s (%s)", ErrFixtureNotFound, req.Method, req.URL.String(), name)
+		}
+
+		return nil, errors.Join(errRead, errFixtureRead)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(raw, &fixture); err != nil {
+		return nil, errors.Join(err, errFixtureRead)
+	}
+
+	body := []byte(fixture.Body)
+
+	var text string
+	if json.Unmarshal(fixture.Body, &text) == nil {
+		body = []byte(text)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.StatusCode, http.StatusText(fixture.StatusCode)),
+		StatusCode:    fixture.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
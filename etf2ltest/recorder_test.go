@@ -0,0 +1,35 @@
+package etf2ltest_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leighmacdonald/etf2l"
+	"github.com/leighmacdonald/etf2l/etf2ltest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	live := etf2l.HTTPExecutorFunc(func(req *http.Request) (*http.Response, error) {
+		return etf2ltest.NewReplayer("../testdata/synthetic").Do(req)
+	})
+
+	client := etf2l.New()
+
+	recorded, errRecord := client.Team(context.Background(), etf2ltest.NewRecorder(dir, live), 2)
+	require.NoError(t, errRecord)
+	require.FileExists(t, filepath.Join(dir, "team_2.json"))
+
+	replayed, errReplay := client.Team(context.Background(), etf2ltest.NewReplayer(dir), 2)
+	require.NoError(t, errReplay)
+	require.Equal(t, recorded, replayed)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "team_2.json")))
+
+	_, errMissing := client.Team(context.Background(), etf2ltest.NewReplayer(dir), 2)
+	require.ErrorIs(t, errMissing, etf2ltest.ErrFixtureNotFound)
+}
@@ -0,0 +1,795 @@
+package etf2ltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/leighmacdonald/etf2l"
+)
+
+// DefaultPerPage matches the default page size used by the live api.
+const DefaultPerPage = 20
+
+// Fixtures holds the data served by a Server. Maps are keyed by the ETF2L id of the player, team or competition
+// the rows belong to.
+//
+// Any steam ids contained within the fixtures must be valid as the client rejects responses containing invalid ids.
+// The country and user recruitment filters resolve the author of a post from Players by steam id, and the country
+// of a team post from Teams, posts which cannot be resolved are left out when either filter is used.
+type Fixtures struct {
+	Players            []etf2l.Player
+	PlayerResults      map[int][]etf2l.PlayerResult
+	PlayerTransfers    map[int][]etf2l.PlayerTransfer
+	Teams              []etf2l.Team
+	TeamTransfers      map[int][]etf2l.TeamTransfer
+	TeamResults        map[int][]etf2l.TeamResult
+	TeamMatches        map[int][]etf2l.TeamResult
+	Bans               []etf2l.Ban
+	Demos              []etf2l.Demo
+	Competitions       []etf2l.CompetitionDetails
+	CompetitionTeams   map[int][]etf2l.CompetitionTeam
+	CompetitionResults map[int][]etf2l.CompetitionResult
+	CompetitionMatches map[int][]etf2l.CompetitionMatch
+	CompetitionTables  map[int]map[string]etf2l.CompetitionTable
+	Matches            []etf2l.Match
+	// MatchDetails is optional, matches without an entry have their details derived from Matches.
+	MatchDetails      map[int]etf2l.MatchDetails
+	Whitelists        map[string]etf2l.Whitelist
+	PlayerRecruitment []etf2l.PlayerRecruitment
+	TeamRecruitment   []etf2l.TeamRecruitment
+}
+
+type failure struct {
+	status    int
+	remaining int // Negative values never run out.
+}
+
+// Server is an in-process fake of the ETF2L api. It serves the provided Fixtures using the same response
+// envelopes and Laravel style pagination as the real api so that it can be used to exercise etf2l.Client.
+type Server struct {
+	*httptest.Server
+	fixtures Fixtures
+	perPage  int
+	mu       sync.Mutex
+	failures map[string]*failure
+	requests map[string]int
+}
+
+// ServerOption configures optional Server behaviour.
+type ServerOption func(server *Server)
+
+// WithPerPage sets the page size used when paginating responses.
+func WithPerPage(perPage int) ServerOption {
+	return func(server *Server) {
+		server.perPage = perPage
+	}
+}
+
+// NewServer starts a fake api server serving fixtures. Callers must Close the server once finished.
+func NewServer(fixtures Fixtures, opts ...ServerOption) *Server {
+	server := &Server{
+		fixtures: fixtures,
+		perPage:  DefaultPerPage,
+		failures: map[string]*failure{},
+		requests: map[string]int{},
+	}
+
+	for _, opt := range opts {
+		opt(server)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /player/{id}", server.player)
+	mux.HandleFunc("GET /player/{id}/results", server.playerResults)
+	mux.HandleFunc("GET /player/{id}/transfers", server.playerTransfers)
+	mux.HandleFunc("GET /team/{id}", server.team)
+	mux.HandleFunc("GET /team/{id}/transfers", server.teamTransfers)
+	mux.HandleFunc("GET /team/{id}/results", server.teamResults)
+	mux.HandleFunc("GET /team/{id}/matches", server.teamMatches)
+	mux.HandleFunc("GET /bans", server.bans)
+	mux.HandleFunc("GET /demos", server.demos)
+	mux.HandleFunc("GET /competition/list", server.competitionList)
+	mux.HandleFunc("GET /competition/{id}", server.competition)
+	mux.HandleFunc("GET /competition/{id}/teams", server.competitionTeams)
+	mux.HandleFunc("GET /competition/{id}/results", server.competitionResults)
+	mux.HandleFunc("GET /competition/{id}/matches", server.competitionMatches)
+	mux.HandleFunc("GET /competition/{id}/tables", server.competitionTables)
+	mux.HandleFunc("GET /matches", server.matches)
+	mux.HandleFunc("GET /matches/{id}", server.matchDetails)
+	mux.HandleFunc("GET /whitelists", server.whitelists)
+	mux.HandleFunc("GET /recruitment/players", server.playerRecruitment)
+	mux.HandleFunc("GET /recruitment/teams", server.teamRecruitment)
+
+	server.Server = httptest.NewServer(server.intercept(mux))
+
+	return server
+}
+
+// NewClient returns a client configured to use the server as its base url.
+func (s *Server) NewClient(opts ...etf2l.Option) *etf2l.Client {
+	return etf2l.New(append(opts, etf2l.WithBaseURL(s.URL))...)
+}
+
+// Fail causes the next count requests to path to respond with status instead of the fixture data. Path may be "*"
+// to match all requests. A count less than 1 fails every request until Reset is called.
+func (s *Server) Fail(path string, status int, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if count < 1 {
+		count = -1
+	}
+
+	s.failures[path] = &failure{status: status, remaining: count}
+}
+
+// Reset removes all injected failures and clears the request counters.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = map[string]*failure{}
+	s.requests = map[string]int{}
+}
+
+// Requests returns the number of requests received for path, including failed ones.
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[path]
+}
+
+func (s *Server) intercept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		if status := s.nextFailure(req.URL.Path); status != 0 {
+			if status == http.StatusTooManyRequests {
+				writer.Header().Set("Retry-After", "1")
+			}
+
+			writeStatus(writer, status)
+
+			return
+		}
+
+		// Pages are numbered from 1. Page 0 is rejected instead of being served as the first page, so that clients
+		// requesting the first page twice are caught.
+		if page := req.URL.Query().Get("page"); page != "" {
+			if number, err := strconv.Atoi(page); err != nil || number < 1 {
+				writeStatus(writer, http.StatusBadRequest)
+
+				return
+			}
+		}
+
+		next.ServeHTTP(writer, req)
+	})
+}
+
+func (s *Server) nextFailure(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests[path]++
+
+	for _, key := range []string{path, "*"} {
+		fail, found := s.failures[key]
+		if !found {
+			continue
+		}
+
+		if fail.remaining > 0 {
+			fail.remaining--
+			if fail.remaining == 0 {
+				delete(s.failures, key)
+			}
+		}
+
+		return fail.status
+	}
+
+	return 0
+}
+
+type status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func okStatus() status {
+	return status{Code: http.StatusOK, Message: "OK"}
+}
+
+func writeJSON(writer http.ResponseWriter, code int, payload any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(code)
+
+	_ = json.NewEncoder(writer).Encode(payload)
+}
+
+func writeStatus(writer http.ResponseWriter, code int) {
+	writeJSON(writer, code, map[string]status{"status": {Code: code, Message: http.StatusText(code)}})
+}
+
+func pathID(req *http.Request) (int, bool) {
+	value, err := strconv.Atoi(req.PathValue("id"))
+
+	return value, err == nil
+}
+
+func queryInt(req *http.Request, key string) (int, bool) {
+	value, err := strconv.Atoi(req.URL.Query().Get(key))
+
+	return value, err == nil
+}
+
+// queryList returns all values for key, accepting both key=a&key=b and key[]=a&key[]=b forms.
+func queryList(req *http.Request, key string) []string {
+	query := req.URL.Query()
+
+	return append(query[key], query[key+"[]"]...)
+}
+
+type pageLink struct {
+	URL    *string `json:"url"`
+	Label  string  `json:"label"`
+	Active bool    `json:"active"`
+}
+
+// page mirrors the Laravel LengthAwarePaginator serialisation used by most of the api.
+type page[T any] struct {
+	CurrentPage  int        `json:"current_page"`
+	Data         []T        `json:"data"`
+	FirstPageURL string     `json:"first_page_url"`
+	From         int        `json:"from"`
+	LastPage     int        `json:"last_page"`
+	LastPageURL  string     `json:"last_page_url"`
+	Links        []pageLink `json:"links"`
+	NextPageURL  *string    `json:"next_page_url"`
+	Path         string     `json:"path"`
+	PerPage      int        `json:"per_page"`
+	PrevPageURL  *string    `json:"prev_page_url"`
+	To           int        `json:"to"`
+	Total        int        `json:"total"`
+}
+
+// resource mirrors the Laravel API resource collection serialisation used by the transfer endpoints.
+type resource[T any] struct {
+	Data  []T `json:"data"`
+	Links struct {
+		First string  `json:"first"`
+		Last  string  `json:"last"`
+		Prev  *string `json:"prev"`
+		Next  *string `json:"next"`
+	} `json:"links"`
+	Meta struct {
+		CurrentPage int        `json:"current_page"`
+		From        int        `json:"from"`
+		LastPage    int        `json:"last_page"`
+		Links       []pageLink `json:"links"`
+		Path        string     `json:"path"`
+		PerPage     int        `json:"per_page"`
+		To          int        `json:"to"`
+		Total       int        `json:"total"`
+	} `json:"meta"`
+}
+
+type pageInfo struct {
+	current, last, perPage, total, from, to int
+	path                                    string
+	pageURL                                 func(number int) string
+}
+
+func (s *Server) pageInfo(req *http.Request, total int) pageInfo {
+	perPage := s.perPage
+	if limit, found := queryInt(req, "limit"); found && limit > 0 {
+		perPage = limit
+	}
+
+	current, found := queryInt(req, "page")
+	if !found {
+		current = 1
+	}
+
+	last := max(1, (total+perPage-1)/perPage)
+	from := min((current-1)*perPage, total)
+	to := min(from+perPage, total)
+	path := "http://" + req.Host + req.URL.Path
+
+	return pageInfo{
+		current: current,
+		last:    last,
+		perPage: perPage,
+		total:   total,
+		from:    from,
+		to:      to,
+		path:    path,
+		// Like the api, links only carry the page number and drop every other query parameter.
+		pageURL: func(number int) string {
+			return path + "?page=" + strconv.Itoa(number)
+		},
+	}
+}
+
+func (p pageInfo) next() *string {
+	if p.current >= p.last {
+		return nil
+	}
+
+	next := p.pageURL(p.current + 1)
+
+	return &next
+}
+
+func (p pageInfo) prev() *string {
+	if p.current <= 1 {
+		return nil
+	}
+
+	prev := p.pageURL(p.current - 1)
+
+	return &prev
+}
+
+func (p pageInfo) links() []pageLink {
+	links := []pageLink{{URL: p.prev(), Label: "&laquo; Previous"}}
+
+	for number := 1; number <= p.last; number++ {
+		link := p.pageURL(number)
+		links = append(links, pageLink{URL: &link, Label: strconv.Itoa(number), Active: number == p.current})
+	}
+
+	return append(links, pageLink{URL: p.next(), Label: "Next &raquo;"})
+}
+
+// Laravel reports from/to as 1 based positions.
+func (p pageInfo) fromTo() (int, int) {
+	if p.from == p.to {
+		return 0, 0
+	}
+
+	return p.from + 1, p.to
+}
+
+func paginate[T any](s *Server, req *http.Request, items []T) page[T] {
+	info := s.pageInfo(req, len(items))
+	from, to := info.fromTo()
+
+	return page[T]{
+		CurrentPage:  info.current,
+		Data:         append([]T{}, items[info.from:info.to]...),
+		FirstPageURL: info.pageURL(1),
+		From:         from,
+		LastPage:     info.last,
+		LastPageURL:  info.pageURL(info.last),
+		Links:        info.links(),
+		NextPageURL:  info.next(),
+		Path:         info.path,
+		PerPage:      info.perPage,
+		PrevPageURL:  info.prev(),
+		To:           to,
+		Total:        info.total,
+	}
+}
+
+func paginateResource[T any](s *Server, req *http.Request, items []T) resource[T] {
+	info := s.pageInfo(req, len(items))
+	from, to := info.fromTo()
+
+	var out resource[T]
+
+	out.Data = append([]T{}, items[info.from:info.to]...)
+	out.Links.First = info.pageURL(1)
+	out.Links.Last = info.pageURL(info.last)
+	out.Links.Prev = info.prev()
+	out.Links.Next = info.next()
+	out.Meta.CurrentPage = info.current
+	out.Meta.From = from
+	out.Meta.LastPage = info.last
+	out.Meta.Links = info.links()
+	out.Meta.Path = info.path
+	out.Meta.PerPage = info.perPage
+	out.Meta.To = to
+	out.Meta.Total = info.total
+
+	return out
+}
+
+func filter[T any](items []T, keep func(item T) bool) []T {
+	var out []T
+
+	for _, item := range items {
+		if keep(item) {
+			out = append(out, item)
+		}
+	}
+
+	return out
+}
+
+// findPlayer resolves a player by either their ETF2L id or any form of steam id.
+func (s *Server) findPlayer(value string) (etf2l.Player, bool) {
+	for _, player := range s.fixtures.Players {
+		if strconv.Itoa(player.ID) == value || player.Steam.ID64.String() == value ||
+			string(player.Steam.ID) == value || string(player.Steam.ID3) == value {
+			return player, true
+		}
+	}
+
+	return etf2l.Player{}, false
+}
+
+func (s *Server) playerID(req *http.Request) (int, bool) {
+	if player, found := s.findPlayer(req.PathValue("id")); found {
+		return player.ID, true
+	}
+
+	return pathID(req)
+}
+
+func (s *Server) player(writer http.ResponseWriter, req *http.Request) {
+	player, found := s.findPlayer(req.PathValue("id"))
+	if !found {
+		writeStatus(writer, http.StatusNotFound)
+
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, map[string]any{"player": player, "status": okStatus()})
+}
+
+func (s *Server) playerResults(writer http.ResponseWriter, req *http.Request) {
+	playerID, found := s.playerID(req)
+	if !found {
+		writeStatus(writer, http.StatusNotFound)
+
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, paginate(s, req, s.fixtures.PlayerResults[playerID]))
+}
+
+func (s *Server) playerTransfers(writer http.ResponseWriter, req *http.Request) {
+	playerID, found := s.playerID(req)
+	if !found {
+		writeStatus(writer, http.StatusNotFound)
+
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, paginateResource(s, req, s.fixtures.PlayerTransfers[playerID]))
+}
+
+func (s *Server) team(writer http.ResponseWriter, req *http.Request) {
+	teamID, _ := pathID(req)
+
+	idx := slices.IndexFunc(s.fixtures.Teams, func(team etf2l.Team) bool { return team.ID == teamID })
+	if idx < 0 {
+		writeStatus(writer, http.StatusNotFound)
+
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, map[string]any{"team": s.fixtures.Teams[idx], "status": okStatus()})
+}
+
+func (s *Server) teamTransfers(writer http.ResponseWriter, req *http.Request) {
+	teamID, _ := pathID(req)
+	writeJSON(writer, http.StatusOK, paginateResource(s, req, s.fixtures.TeamTransfers[teamID]))
+}
+
+func (s *Server) teamResults(writer http.ResponseWriter, req *http.Request) {
+	teamID, _ := pathID(req)
+	writeJSON(writer, http.StatusOK, paginate(s, req, s.fixtures.TeamResults[teamID]))
+}
+
+func (s *Server) teamMatches(writer http.ResponseWriter, req *http.Request) {
+	teamID, _ := pathID(req)
+	writeJSON(writer, http.StatusOK, paginate(s, req, s.fixtures.TeamMatches[teamID]))
+}
+
+func (s *Server) bans(writer http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	playerID, byPlayer := queryInt(req, "player")
+	banStatus := query.Get("status")
+	reason := query.Get("reason")
+
+	bans := filter(s.fixtures.Bans, func(ban etf2l.Ban) bool {
+		// Bans carry no player id, so they are matched using the id within the profile url instead.
+		if byPlayer && !strings.HasSuffix(ban.Profile, fmt.Sprintf("/%d/", playerID)) {
+			return false
+		}
+
+		if banStatus == "active" && ban.Expired || banStatus == "expired" && !ban.Expired {
+			return false
+		}
+
+		return reason == "" || strings.EqualFold(ban.Reason, reason)
+	})
+
+	writeJSON(writer, http.StatusOK, map[string]any{"bans": paginate(s, req, bans)})
+}
+
+func (s *Server) demos(writer http.ResponseWriter, req *http.Request) {
+	playerID, byPlayer := queryInt(req, "player")
+	from, byFrom := queryInt(req, "from")
+	to, byTo := queryInt(req, "to")
+
+	demos := filter(s.fixtures.Demos, func(demo etf2l.Demo) bool {
+		switch {
+		case byPlayer && demo.Owner != playerID,
+			byFrom && demo.Time < from,
+			byTo && demo.Time > to:
+			return false
+		default:
+			return true
+		}
+	})
+
+	writeJSON(writer, http.StatusOK, map[string]any{"demos": paginate(s, req, demos), "status": okStatus()})
+}
+
+func toCompetition(details etf2l.CompetitionDetails) etf2l.Competition {
+	competition := etf2l.Competition{
+		Category:    details.Category,
+		Description: details.Description,
+		ID:          details.ID,
+		Name:        details.Name,
+		Archived:    details.Archived,
+		Type:        details.Type,
+	}
+	competition.Urls.Matches = details.Urls.Matches
+	competition.Urls.Results = details.Urls.Results
+	competition.Urls.Self = details.Urls.Self
+	competition.Urls.Teams = details.Urls.Teams
+
+	return competition
+}
+
+func (s *Server) competitionList(writer http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	archived, byArchived := queryInt(req, "archived")
+
+	competitions := filter(s.fixtures.Competitions, func(details etf2l.CompetitionDetails) bool {
+		if byArchived && details.Archived != (archived == etf2l.Archived) {
+			return false
+		}
+
+		if name := query.Get("name"); name != "" && !strings.Contains(strings.ToLower(details.Name), strings.ToLower(name)) {
+			return false
+		}
+
+		if category := query.Get("category"); category != "" && details.Category != category {
+			return false
+		}
+
+		return query.Get("comp_type") == "" || details.Type == query.Get("comp_type")
+	})
+
+	list := make([]etf2l.Competition, len(competitions))
+	for idx, details := range competitions {
+		list[idx] = toCompetition(details)
+	}
+
+	writeJSON(writer, http.StatusOK, map[string]any{"competitions": paginate(s, req, list)})
+}
+
+func (s *Server) competition(writer http.ResponseWriter, req *http.Request) {
+	competitionID, _ := pathID(req)
+
+	idx := slices.IndexFunc(s.fixtures.Competitions, func(details etf2l.CompetitionDetails) bool {
+		return details.ID == competitionID
+	})
+	if idx < 0 {
+		writeStatus(writer, http.StatusNotFound)
+
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, map[string]any{"competition": s.fixtures.Competitions[idx], "status": okStatus()})
+}
+
+func (s *Server) competitionTeams(writer http.ResponseWriter, req *http.Request) {
+	competitionID, _ := pathID(req)
+	writeJSON(writer, http.StatusOK, map[string]any{
+		"teams":  paginate(s, req, s.fixtures.CompetitionTeams[competitionID]),
+		"status": okStatus(),
+	})
+}
+
+func (s *Server) competitionResults(writer http.ResponseWriter, req *http.Request) {
+	competitionID, _ := pathID(req)
+	writeJSON(writer, http.StatusOK, map[string]any{
+		"results": paginate(s, req, s.fixtures.CompetitionResults[competitionID]),
+		"status":  okStatus(),
+	})
+}
+
+func (s *Server) competitionMatches(writer http.ResponseWriter, req *http.Request) {
+	competitionID, _ := pathID(req)
+	writeJSON(writer, http.StatusOK, map[string]any{
+		"matches": paginate(s, req, s.fixtures.CompetitionMatches[competitionID]),
+		"status":  okStatus(),
+	})
+}
+
+func (s *Server) competitionTables(writer http.ResponseWriter, req *http.Request) {
+	competitionID, _ := pathID(req)
+
+	tables, found := s.fixtures.CompetitionTables[competitionID]
+	if !found {
+		writeStatus(writer, http.StatusNotFound)
+
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, map[string]any{"tables": tables, "status": okStatus()})
+}
+
+func matchFilter(req *http.Request) func(match etf2l.Match) bool {
+	query := req.URL.Query()
+	clan1, byClan1 := queryInt(req, "clan1")
+	clan2, byClan2 := queryInt(req, "clan2")
+	versus, byVersus := queryInt(req, "vs")
+	scheduled, byScheduled := queryInt(req, "scheduled")
+	competition, byCompetition := queryInt(req, "competition")
+	from, byFrom := queryInt(req, "from")
+	to, byTo := queryInt(req, "to")
+
+	return func(match etf2l.Match) bool {
+		switch {
+		case byClan1 && match.Clan1.ID != clan1,
+			byClan2 && match.Clan2.ID != clan2,
+			byVersus && match.Clan1.ID != versus && match.Clan2.ID != versus,
+			byScheduled && (scheduled == 1) != (match.Submitted == 0),
+			byCompetition && match.Competition.ID != competition,
+			byFrom && match.Time < from,
+			byTo && match.Time > to,
+			query.Get("division") != "" && match.Division.Name != query.Get("division"),
+			query.Get("round") != "" && match.Round != query.Get("round"),
+			query.Get("team_type") != "" && match.Competition.Type != query.Get("team_type"):
+			return false
+		default:
+			return true
+		}
+	}
+}
+
+func (s *Server) matches(writer http.ResponseWriter, req *http.Request) {
+	matches := filter(s.fixtures.Matches, matchFilter(req))
+	writeJSON(writer, http.StatusOK, map[string]any{"results": paginate(s, req, matches), "status": okStatus()})
+}
+
+func toMatchDetails(match etf2l.Match) etf2l.MatchDetails {
+	details := etf2l.MatchDetails{
+		Clan1:       match.Clan1,
+		Clan2:       match.Clan2,
+		Competition: match.Competition,
+		Defaultwin:  match.Defaultwin,
+		Division:    match.Division,
+		ID:          match.ID,
+		Maps:        match.Maps,
+		R1:          match.R1,
+		R2:          match.R2,
+		Round:       match.Round,
+		Time:        match.Time,
+		Submitted:   match.Submitted,
+		Week:        match.Week,
+	}
+	details.Urls.Self = match.Urls.Self
+	details.Urls.API = match.Urls.API
+
+	return details
+}
+
+func (s *Server) matchDetails(writer http.ResponseWriter, req *http.Request) {
+	matchID, _ := pathID(req)
+
+	details, found := s.fixtures.MatchDetails[matchID]
+	if !found {
+		idx := slices.IndexFunc(s.fixtures.Matches, func(match etf2l.Match) bool { return match.ID == matchID })
+		if idx < 0 {
+			writeStatus(writer, http.StatusNotFound)
+
+			return
+		}
+
+		details = toMatchDetails(s.fixtures.Matches[idx])
+	}
+
+	writeJSON(writer, http.StatusOK, map[string]any{"match": details, "status": okStatus()})
+}
+
+func (s *Server) whitelists(writer http.ResponseWriter, _ *http.Request) {
+	writeJSON(writer, http.StatusOK, map[string]any{"whitelists": s.fixtures.Whitelists, "status": okStatus()})
+}
+
+// recruitmentPost holds the fields of a player or team post which the recruitment filters apply to.
+type recruitmentPost struct {
+	classes  etf2l.PlayerClasses
+	skill    string
+	postType string
+	// author is the ETF2L id of the player who made the post, 0 when they are not part of the fixtures.
+	author int
+	// country of the player or team recruiting, empty when they are not part of the fixtures.
+	country string
+}
+
+// author resolves the player who made a post from the steam id of the post.
+func (s *Server) author(steam etf2l.SteamPlayer) (etf2l.Player, bool) {
+	if !steam.ID64.Valid() {
+		return etf2l.Player{}, false
+	}
+
+	return s.findPlayer(steam.ID64.String())
+}
+
+// recruitmentFilter applies the class, skill, type, country and user filters. Posts carry neither a country nor
+// the id of their author, so both are looked up from the Players and Teams fixtures and posts which cannot be
+// resolved never match those filters.
+func recruitmentFilter(req *http.Request) func(post recruitmentPost) bool {
+	classes := queryList(req, "class")
+	skills := queryList(req, "skill")
+	teamType := req.URL.Query().Get("type")
+	country := req.URL.Query().Get("country")
+	user, byUser := queryInt(req, "user")
+
+	return func(post recruitmentPost) bool {
+		if teamType != "" && post.postType != teamType {
+			return false
+		}
+
+		if len(skills) > 0 && !slices.Contains(skills, post.skill) {
+			return false
+		}
+
+		if country != "" && !strings.EqualFold(post.country, country) {
+			return false
+		}
+
+		if byUser && post.author != user {
+			return false
+		}
+
+		return len(classes) == 0 || slices.ContainsFunc(classes, func(class string) bool {
+			return slices.Contains(post.classes, class)
+		})
+	}
+}
+
+func (s *Server) playerRecruitment(writer http.ResponseWriter, req *http.Request) {
+	keep := recruitmentFilter(req)
+	posts := filter(s.fixtures.PlayerRecruitment, func(post etf2l.PlayerRecruitment) bool {
+		author, _ := s.author(post.Steam)
+
+		return keep(recruitmentPost{
+			classes: post.Classes, skill: post.Skill, postType: post.Type, author: author.ID, country: author.Country,
+		})
+	})
+
+	writeJSON(writer, http.StatusOK, map[string]any{"recruitment": paginate(s, req, posts), "status": okStatus()})
+}
+
+func (s *Server) teamRecruitment(writer http.ResponseWriter, req *http.Request) {
+	keep := recruitmentFilter(req)
+	posts := filter(s.fixtures.TeamRecruitment, func(post etf2l.TeamRecruitment) bool {
+		author, _ := s.author(post.Steam)
+		recruiting := recruitmentPost{classes: post.Classes, skill: post.Skill, postType: post.Type, author: author.ID}
+
+		teamID, _ := strconv.Atoi(path.Base(strings.TrimSuffix(post.Urls.Team, "/")))
+		if idx := slices.IndexFunc(s.fixtures.Teams, func(team etf2l.Team) bool { return team.ID == teamID }); idx >= 0 {
+			recruiting.country = s.fixtures.Teams[idx].Country
+		}
+
+		return keep(recruiting)
+	})
+
+	writeJSON(writer, http.StatusOK, map[string]any{"recruitment": paginate(s, req, posts), "status": okStatus()})
+}
@@ -0,0 +1,194 @@
+package etf2ltest_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/leighmacdonald/etf2l"
+	"github.com/leighmacdonald/etf2l/etf2ltest"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+func testFixtures() etf2ltest.Fixtures {
+	player := etf2l.Player{ID: 20834, Name: "b4nny", Country: "Canada"}
+	player.Steam.ID64 = steamid.New("76561197970669109")
+
+	stranger := etf2l.SteamPlayer{ID64: steamid.New("76561198203516436")}
+
+	teamPost := etf2l.TeamRecruitment{ID: 3, Name: "Froyotech", Type: "6on6", Classes: etf2l.PlayerClasses{"Medic"}, Steam: player.Steam}
+	teamPost.Urls.Team = "https://etf2l.org/teams/2/"
+
+	transfers := make([]etf2l.TeamTransfer, 7)
+	for idx := range transfers {
+		transfers[idx].Who = etf2l.TransferPlayerInfo{ID: idx + 1, Name: fmt.Sprintf("player%d", idx)}
+		transfers[idx].Who.Steam.ID64 = steamid.New(int64(76561197960265729 + idx))
+		transfers[idx].By = transfers[idx].Who
+		transfers[idx].Team.ID = 2
+		transfers[idx].Type = "joined"
+	}
+
+	return etf2ltest.Fixtures{
+		Players:       []etf2l.Player{player},
+		Teams:         []etf2l.Team{{ID: 2, Name: "Froyotech", Country: "France"}},
+		TeamTransfers: map[int][]etf2l.TeamTransfer{2: transfers},
+		Bans: []etf2l.Ban{
+			{Name: "b4nny", Profile: "https://etf2l.org/forum/user/20834/", Reason: "VAC", Expired: true, Steamid64: player.Steam.ID64},
+			{Name: "b4nny", Profile: "https://etf2l.org/forum/user/20834/", Reason: "Cheating", Steamid64: player.Steam.ID64},
+			{Name: "cheater", Profile: "https://etf2l.org/forum/user/5000/", Reason: "VAC", Steamid64: stranger.ID64},
+		},
+		PlayerRecruitment: []etf2l.PlayerRecruitment{
+			{ID: 1, Name: "b4nny", Type: "6on6", Classes: etf2l.PlayerClasses{"Scout"}, Steam: player.Steam},
+			{ID: 2, Name: "unknown", Type: "6on6", Classes: etf2l.PlayerClasses{"Scout"}, Steam: stranger},
+		},
+		TeamRecruitment: []etf2l.TeamRecruitment{teamPost},
+		Matches: []etf2l.Match{
+			{ID: 1, Clan1: etf2l.MatchClan{ID: 2}, Clan2: etf2l.MatchClan{ID: 3}, Submitted: 1},
+			{ID: 2, Clan1: etf2l.MatchClan{ID: 4}, Clan2: etf2l.MatchClan{ID: 2}},
+		},
+	}
+}
+
+func TestServer(t *testing.T) {
+	server := etf2ltest.NewServer(testFixtures(), etf2ltest.WithPerPage(3))
+	defer server.Close()
+
+	client := server.NewClient()
+	ctx := context.Background()
+
+	t.Run("player", func(t *testing.T) {
+		bySteam, err := client.Player(ctx, server.Client(), "76561197970669109")
+		require.NoError(t, err)
+		require.Equal(t, 20834, bySteam.ID)
+
+		byID, errID := client.Player(ctx, server.Client(), "20834")
+		require.NoError(t, errID)
+		require.Equal(t, bySteam, byID)
+
+		_, errMissing := client.Player(ctx, server.Client(), "1")
+		require.ErrorIs(t, errMissing, etf2l.ErrNotFound)
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		firstPage, err := client.TeamTransfers(ctx, server.Client(), 2, etf2l.BaseOpts{Recursive: false})
+		require.NoError(t, err)
+		require.Len(t, firstPage, 3)
+
+		all, errAll := client.TeamTransfers(ctx, server.Client(), 2, etf2l.BaseOpts{Recursive: true})
+		require.NoError(t, errAll)
+		require.Len(t, all, 7)
+		require.Equal(t, 3, server.Requests("/team/2/transfers")-1)
+	})
+
+	t.Run("match_details", func(t *testing.T) {
+		match, err := client.MatchDetails(ctx, server.Client(), 2)
+		require.NoError(t, err)
+		require.Equal(t, 4, match.Clan1.ID)
+	})
+
+	t.Run("ban_filters", func(t *testing.T) {
+		byPlayer, err := client.Bans(ctx, server.Client(), etf2l.BanOpts{Recursive: etf2l.BaseOpts{}, PlayerID: 20834})
+		require.NoError(t, err)
+		require.Len(t, byPlayer, 2)
+
+		active, errActive := client.Bans(ctx, server.Client(), etf2l.BanOpts{Recursive: etf2l.BaseOpts{}, PlayerID: 20834, Status: "active"})
+		require.NoError(t, errActive)
+		require.Len(t, active, 1)
+		require.Equal(t, "Cheating", active[0].Reason)
+
+		vac, errVAC := client.Bans(ctx, server.Client(), etf2l.BanOpts{Recursive: etf2l.BaseOpts{}, Reason: "VAC"})
+		require.NoError(t, errVAC)
+		require.Len(t, vac, 2)
+	})
+
+	t.Run("recruitment_filters", func(t *testing.T) {
+		byCountry, err := client.PlayerRecruitment(ctx, server.Client(), etf2l.RecruitmentOpts{Country: "Canada"})
+		require.NoError(t, err)
+		require.Len(t, byCountry, 1)
+		require.Equal(t, 1, byCountry[0].ID)
+
+		byUser, errUser := client.PlayerRecruitment(ctx, server.Client(), etf2l.RecruitmentOpts{User: 20834})
+		require.NoError(t, errUser)
+		require.Len(t, byUser, 1)
+
+		teams, errTeams := client.TeamRecruitment(ctx, server.Client(), etf2l.RecruitmentOpts{Country: "France"})
+		require.NoError(t, errTeams)
+		require.Len(t, teams, 1)
+
+		none, errNone := client.TeamRecruitment(ctx, server.Client(), etf2l.RecruitmentOpts{Country: "Canada"})
+		require.NoError(t, errNone)
+		require.Empty(t, none)
+	})
+
+	t.Run("filtered_pages", func(t *testing.T) {
+		fixtures := testFixtures()
+		fixtures.Bans = append(fixtures.Bans, fixtures.Bans...)
+		fixtures.PlayerRecruitment = append(fixtures.PlayerRecruitment, fixtures.PlayerRecruitment...)
+
+		for idx := range 6 {
+			fixtures.Demos = append(fixtures.Demos, etf2l.Demo{ID: idx + 1, Owner: 20834 + idx%2})
+		}
+
+		// Links of the second page only carry the page number, the filters must be applied by the client again.
+		paged := etf2ltest.NewServer(fixtures, etf2ltest.WithPerPage(1))
+		defer paged.Close()
+
+		pagedClient := paged.NewClient()
+
+		bans, err := pagedClient.Bans(ctx, paged.Client(), etf2l.BanOpts{Recursive: etf2l.BaseOpts{Recursive: true}, Reason: "Cheating"})
+		require.NoError(t, err)
+		require.Len(t, bans, 2)
+
+		demos, errDemos := pagedClient.Demos(ctx, paged.Client(), &etf2l.DemoOpts{Recursive: etf2l.BaseOpts{Recursive: true}, PlayerID: "20834"})
+		require.NoError(t, errDemos)
+		require.Len(t, demos, 3)
+
+		for _, demo := range demos {
+			require.Equal(t, 20834, demo.Owner)
+		}
+
+		posts, errPosts := pagedClient.PlayerRecruitment(ctx, paged.Client(), etf2l.RecruitmentOpts{BaseOpts: etf2l.BaseOpts{Recursive: true}, Country: "Canada"})
+		require.NoError(t, errPosts)
+		require.Len(t, posts, 2)
+
+		matches, _, errMatches := pagedClient.Matches(ctx, paged.Client(), &etf2l.MatchesOpts{BaseOpts: etf2l.BaseOpts{Recursive: true}, Vs: 3})
+		require.NoError(t, errMatches)
+		require.Len(t, matches, 1)
+	})
+
+	t.Run("matches_pages", func(t *testing.T) {
+		matches := make([]etf2l.Match, 2500)
+		for idx := range matches {
+			matches[idx].ID = idx + 1
+		}
+
+		paged := etf2ltest.NewServer(etf2ltest.Fixtures{Matches: matches})
+		defer paged.Close()
+
+		all, total, err := paged.NewClient().Matches(ctx, paged.Client(), etf2l.BaseOpts{Recursive: true})
+		require.NoError(t, err)
+		require.Equal(t, 2500, total)
+		require.Equal(t, matches, all)
+		require.Equal(t, 2, paged.Requests("/matches"))
+
+		_, errZero := paged.NewClient().MatchesPage(ctx, paged.Client(), 0, 10)
+		require.ErrorContains(t, errZero, "400")
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		server.Fail("/team/2", http.StatusTooManyRequests, 1)
+		_, errLimited := client.Team(ctx, server.Client(), 2)
+		require.ErrorContains(t, errLimited, "Rate limited")
+
+		server.Fail("*", http.StatusInternalServerError, 0)
+		_, errServer := client.Team(ctx, server.Client(), 2)
+		require.ErrorContains(t, errServer, "500")
+
+		server.Reset()
+		team, err := client.Team(ctx, server.Client(), 2)
+		require.NoError(t, err)
+		require.Equal(t, "Froyotech", team.Name)
+	})
+}
@@ -0,0 +1,224 @@
+package export
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var errNotStruct = errors.New("Export rows must be structs")
+
+type kind int
+
+const (
+	kindString kind = iota
+	kindInt
+	kindFloat
+	kindBool
+)
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// column is a single flattened leaf field of a row type.
+type column struct {
+	name string
+	kind kind
+	// path is the field index path from the row struct to the leaf.
+	path   []int
+	encode func(value reflect.Value) (any, bool)
+}
+
+// value extracts the column from a row, returning false when the value is missing, eg: a nil pointer.
+func (c column) value(row reflect.Value) (any, bool) {
+	for _, idx := range c.path {
+		for row.Kind() == reflect.Pointer {
+			if row.IsNil() {
+				return nil, false
+			}
+
+			row = row.Elem()
+		}
+
+		row = row.Field(idx)
+	}
+
+	return c.encode(row)
+}
+
+// Columns returns the flattened column names used for rows of type T. Nested struct fields are joined with a
+// dot using their json names, eg: clan1.name. The names only change when the underlying types do.
+func Columns[T any]() ([]string, error) {
+	columns, err := columnsFor(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(columns))
+	for idx, col := range columns {
+		names[idx] = col.name
+	}
+
+	return names, nil
+}
+
+func columnsFor(typ reflect.Type) ([]column, error) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil, errors.Wrapf(errNotStruct, "Cannot export %s", typ)
+	}
+
+	var columns []column
+
+	walk("", typ, nil, &columns)
+
+	return columns, nil
+}
+
+func walk(prefix string, typ reflect.Type, path []int, columns *[]column) {
+	for idx := range typ.NumField() {
+		field := typ.Field(idx)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldPath := append(append([]int{}, path...), idx)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		switch {
+		case name == "-":
+			continue
+		case name == "" && field.Anonymous && deref(field.Type).Kind() == reflect.Struct:
+			walk(prefix, deref(field.Type), fieldPath, columns)
+
+			continue
+		case name == "":
+			name = field.Name
+		}
+
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		leaf := deref(field.Type)
+		if leaf.Kind() == reflect.Struct && !isMarshaler(leaf) {
+			walk(name, leaf, fieldPath, columns)
+
+			continue
+		}
+
+		*columns = append(*columns, leafColumn(name, leaf, fieldPath))
+	}
+}
+
+func deref(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	return typ
+}
+
+func isMarshaler(typ reflect.Type) bool {
+	return typ.Implements(jsonMarshalerType) || reflect.PointerTo(typ).Implements(jsonMarshalerType) ||
+		typ.Implements(textMarshalerType) || reflect.PointerTo(typ).Implements(textMarshalerType)
+}
+
+func leafColumn(name string, typ reflect.Type, path []int) column {
+	col := column{name: name, path: path, kind: kindString, encode: encodeJSON}
+
+	if isMarshaler(typ) {
+		return col
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		col.kind = kindBool
+		col.encode = leaf(func(value reflect.Value) any { return value.Bool() })
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		col.kind = kindInt
+		col.encode = leaf(func(value reflect.Value) any { return value.Int() })
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		col.kind = kindInt
+		col.encode = leaf(func(value reflect.Value) any { return int64(value.Uint()) })
+	case reflect.Float32, reflect.Float64:
+		col.kind = kindFloat
+		col.encode = leaf(func(value reflect.Value) any { return value.Float() })
+	case reflect.String:
+		col.encode = leaf(func(value reflect.Value) any { return value.String() })
+	case reflect.Slice, reflect.Array:
+		if isScalar(typ.Elem()) {
+			col.encode = leaf(joinScalars)
+		}
+	default:
+	}
+
+	return col
+}
+
+// leaf wraps an encoder so that nil pointers are reported as missing.
+func leaf(encode func(value reflect.Value) any) func(value reflect.Value) (any, bool) {
+	return func(value reflect.Value) (any, bool) {
+		for value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				return nil, false
+			}
+
+			value = value.Elem()
+		}
+
+		return encode(value), true
+	}
+}
+
+func isScalar(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return !isMarshaler(typ)
+	default:
+		return false
+	}
+}
+
+// joinScalars joins slices of scalar values with a semicolon, eg: a list of maps.
+func joinScalars(value reflect.Value) any {
+	items := make([]string, value.Len())
+	for idx := range value.Len() {
+		items[idx] = fmt.Sprint(value.Index(idx).Interface())
+	}
+
+	return strings.Join(items, ";")
+}
+
+// encodeJSON encodes values without a flat representation as json, json strings are unquoted.
+func encodeJSON(value reflect.Value) (any, bool) {
+	if (value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) && value.IsNil() {
+		return nil, false
+	}
+
+	// Copy into an addressable value so pointer receiver marshalers are used.
+	addressable := reflect.New(value.Type())
+	addressable.Elem().Set(value)
+
+	encoded, err := json.Marshal(addressable.Interface())
+	if err != nil {
+		return nil, false
+	}
+
+	if unquoted, errUnquote := strconv.Unquote(string(encoded)); errUnquote == nil {
+		return unquoted, true
+	}
+
+	return string(encoded), true
+}
@@ -0,0 +1,241 @@
+// Package export writes api results as CSV, NDJSON or Parquet. Rows are written one at a time so large dumps,
+// eg: every ban or match, never need to be held in memory.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"iter"
+	"reflect"
+	"strconv"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/pkg/errors"
+)
+
+type Format string
+
+const (
+	CSV     Format = "csv"
+	NDJSON  Format = "ndjson"
+	Parquet Format = "parquet"
+)
+
+var errFormat = errors.New("Unknown export format")
+
+// Writer streams rows of type T. Close must be called to flush any buffered rows, it does not close the
+// underlying io.Writer.
+type Writer[T any] interface {
+	Write(row T) error
+	Close() error
+}
+
+// NewWriter creates a writer for the given format.
+func NewWriter[T any](writer io.Writer, format Format) (Writer[T], error) {
+	switch format {
+	case CSV:
+		return NewCSVWriter[T](writer)
+	case NDJSON:
+		return NewNDJSONWriter[T](writer), nil
+	case Parquet:
+		return NewParquetWriter[T](writer)
+	default:
+		return nil, errors.Wrapf(errFormat, "Cannot export %q", format)
+	}
+}
+
+// Write writes every row of the sequence, eg: slices.Values(bans), in the given format.
+func Write[T any](writer io.Writer, format Format, rows iter.Seq[T]) error {
+	exporter, errWriter := NewWriter[T](writer, format)
+	if errWriter != nil {
+		return errWriter
+	}
+
+	for row := range rows {
+		if err := exporter.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return exporter.Close()
+}
+
+// CSVWriter writes rows as CSV with nested fields flattened into columns, see Columns.
+type CSVWriter[T any] struct {
+	writer  *csv.Writer
+	columns []column
+	record  []string
+}
+
+// NewCSVWriter creates a CSV writer and writes the header row.
+func NewCSVWriter[T any](writer io.Writer) (*CSVWriter[T], error) {
+	columns, errColumns := columnsFor(reflect.TypeFor[T]())
+	if errColumns != nil {
+		return nil, errColumns
+	}
+
+	csvWriter := &CSVWriter[T]{writer: csv.NewWriter(writer), columns: columns, record: make([]string, len(columns))}
+
+	for idx, col := range columns {
+		csvWriter.record[idx] = col.name
+	}
+
+	if err := csvWriter.writer.Write(csvWriter.record); err != nil {
+		return nil, errors.Wrap(err, "Failed to write header")
+	}
+
+	return csvWriter, nil
+}
+
+func (w *CSVWriter[T]) Write(row T) error {
+	value := reflect.ValueOf(row)
+
+	for idx, col := range w.columns {
+		w.record[idx] = ""
+
+		leafValue, found := col.value(value)
+		if !found {
+			continue
+		}
+
+		switch typed := leafValue.(type) {
+		case string:
+			w.record[idx] = typed
+		case int64:
+			w.record[idx] = strconv.FormatInt(typed, 10)
+		case float64:
+			w.record[idx] = strconv.FormatFloat(typed, 'f', -1, 64)
+		case bool:
+			w.record[idx] = strconv.FormatBool(typed)
+		}
+	}
+
+	if err := w.writer.Write(w.record); err != nil {
+		return errors.Wrap(err, "Failed to write row")
+	}
+
+	return nil
+}
+
+func (w *CSVWriter[T]) Close() error {
+	w.writer.Flush()
+
+	return w.writer.Error()
+}
+
+// NDJSONWriter writes each row as a single line of json, keeping nested fields intact.
+type NDJSONWriter[T any] struct {
+	encoder *json.Encoder
+}
+
+func NewNDJSONWriter[T any](writer io.Writer) *NDJSONWriter[T] {
+	return &NDJSONWriter[T]{encoder: json.NewEncoder(writer)}
+}
+
+func (w *NDJSONWriter[T]) Write(row T) error {
+	if err := w.encoder.Encode(row); err != nil {
+		return errors.Wrap(err, "Failed to write row")
+	}
+
+	return nil
+}
+
+func (w *NDJSONWriter[T]) Close() error {
+	return nil
+}
+
+// DefaultRowGroupSize is the number of rows buffered before a parquet row group is flushed.
+const DefaultRowGroupSize = 10000
+
+// ParquetWriter writes rows as a Parquet file using the same flattened, optional, columns as the CSV writer.
+type ParquetWriter[T any] struct {
+	writer  *parquet.Writer
+	columns []column
+	// indexes maps each column to its parquet column index, parquet orders the columns of a group by name.
+	indexes []int
+	row     parquet.Row
+	// RowGroupSize sets how many rows are buffered in memory before being flushed as a row group.
+	RowGroupSize int
+	buffered     int
+}
+
+func NewParquetWriter[T any](writer io.Writer) (*ParquetWriter[T], error) {
+	columns, errColumns := columnsFor(reflect.TypeFor[T]())
+	if errColumns != nil {
+		return nil, errColumns
+	}
+
+	group := parquet.Group{}
+
+	for _, col := range columns {
+		var node parquet.Node
+
+		switch col.kind {
+		case kindInt:
+			node = parquet.Int(64)
+		case kindFloat:
+			node = parquet.Leaf(parquet.DoubleType)
+		case kindBool:
+			node = parquet.Leaf(parquet.BooleanType)
+		case kindString:
+			node = parquet.String()
+		}
+
+		group[col.name] = parquet.Optional(node)
+	}
+
+	schema := parquet.NewSchema(reflect.TypeFor[T]().Name(), group)
+	indexes := make([]int, len(columns))
+
+	for idx, col := range columns {
+		leafColumn, _ := schema.Lookup(col.name)
+		indexes[idx] = leafColumn.ColumnIndex
+	}
+
+	return &ParquetWriter[T]{
+		writer:       parquet.NewWriter(writer, schema, parquet.Compression(&parquet.Snappy)),
+		columns:      columns,
+		indexes:      indexes,
+		row:          make(parquet.Row, len(columns)),
+		RowGroupSize: DefaultRowGroupSize,
+	}, nil
+}
+
+func (w *ParquetWriter[T]) Write(row T) error {
+	value := reflect.ValueOf(row)
+
+	for idx, col := range w.columns {
+		leafValue, found := col.value(value)
+		if !found {
+			w.row[w.indexes[idx]] = parquet.NullValue().Level(0, 0, w.indexes[idx])
+
+			continue
+		}
+
+		w.row[w.indexes[idx]] = parquet.ValueOf(leafValue).Level(0, 1, w.indexes[idx])
+	}
+
+	if _, err := w.writer.WriteRows([]parquet.Row{w.row}); err != nil {
+		return errors.Wrap(err, "Failed to write row")
+	}
+
+	w.buffered++
+	if w.RowGroupSize > 0 && w.buffered >= w.RowGroupSize {
+		w.buffered = 0
+
+		if err := w.writer.Flush(); err != nil {
+			return errors.Wrap(err, "Failed to flush row group")
+		}
+	}
+
+	return nil
+}
+
+func (w *ParquetWriter[T]) Close() error {
+	if err := w.writer.Close(); err != nil {
+		return errors.Wrap(err, "Failed to close parquet writer")
+	}
+
+	return nil
+}
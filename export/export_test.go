@@ -0,0 +1,116 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/leighmacdonald/etf2l"
+	"github.com/leighmacdonald/etf2l/export"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+func testMatches() []etf2l.Match {
+	return []etf2l.Match{
+		{
+			ID:          1,
+			Clan1:       etf2l.MatchClan{ID: 10, Name: "one"},
+			Clan2:       etf2l.MatchClan{ID: 20, Name: "two, the sequel"},
+			Competition: etf2l.MatchCompetition{ID: 5, Name: "Season 1"},
+			Maps:        []string{"cp_process_final", "koth_product_final"},
+			R1:          3,
+			Defaultwin:  true,
+		},
+		{ID: 2, Clan1: etf2l.MatchClan{ID: 20, Name: "two"}},
+	}
+}
+
+func TestColumns(t *testing.T) {
+	columns, err := export.Columns[etf2l.Match]()
+	require.NoError(t, err)
+	require.Equal(t, []string{"clan1.country", "clan1.drop", "clan1.id", "clan1.name"}, columns[:4])
+	require.Contains(t, columns, "urls.self")
+
+	_, errNotStruct := export.Columns[int]()
+	require.Error(t, errNotStruct)
+}
+
+func TestCSV(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, export.Write(&out, export.CSV, slices.Values(testMatches())))
+
+	records, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	row := map[string]string{}
+	for idx, column := range records[0] {
+		row[column] = records[1][idx]
+	}
+
+	require.Equal(t, "two, the sequel", row["clan2.name"])
+	require.Equal(t, "cp_process_final;koth_product_final", row["maps"])
+	require.Equal(t, "true", row["defaultwin"])
+	require.Equal(t, "3", row["r1"])
+
+	var bans bytes.Buffer
+	require.NoError(t, export.Write(&bans, export.CSV, slices.Values([]etf2l.Ban{
+		{Steamid64: steamid.New("76561198203516436"), Name: "cheater"},
+	})))
+	require.Contains(t, bans.String(), "76561198203516436,,false,")
+}
+
+func TestNDJSON(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, export.Write(&out, export.NDJSON, slices.Values(testMatches())))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var match etf2l.Match
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &match))
+	require.Equal(t, testMatches()[0].Clan2, match.Clan2)
+}
+
+func TestParquet(t *testing.T) {
+	var out bytes.Buffer
+
+	writer, errWriter := export.NewParquetWriter[etf2l.Match](&out)
+	require.NoError(t, errWriter)
+
+	writer.RowGroupSize = 1
+
+	for _, match := range testMatches() {
+		require.NoError(t, writer.Write(match))
+	}
+
+	require.NoError(t, writer.Close())
+
+	file, errOpen := parquet.OpenFile(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	require.NoError(t, errOpen)
+	require.Equal(t, int64(2), file.NumRows())
+	require.Len(t, file.RowGroups(), 2)
+
+	name, found := file.Schema().Lookup("clan2.name")
+	require.True(t, found)
+
+	rows := make([]parquet.Row, 1)
+	reader := parquet.NewReader(bytes.NewReader(out.Bytes()))
+
+	count, errRead := reader.ReadRows(rows)
+	if errRead != nil {
+		require.ErrorIs(t, errRead, io.EOF)
+	}
+
+	require.Equal(t, 1, count)
+	require.Equal(t, "two, the sequel", rows[0][name.ColumnIndex].String())
+
+	_, errFormat := export.NewWriter[etf2l.Match](&out, "xml")
+	require.Error(t, errFormat)
+}
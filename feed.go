@@ -0,0 +1,347 @@
+package etf2l
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RecruitmentKind distinguishes player and team recruitment posts, their ids overlap.
+type RecruitmentKind string
+
+const (
+	RecruitmentPlayers RecruitmentKind = "players"
+	RecruitmentTeams   RecruitmentKind = "teams"
+)
+
+const jsonFeedVersion = "https://jsonfeed.org/version/1.1"
+
+// RecruitmentEntryID returns the stable id used for a recruitment post in feeds.
+func RecruitmentEntryID(kind RecruitmentKind, postID int) string {
+	return fmt.Sprintf("tag:etf2l.org,2009:recruitment/%s/%d", kind, postID)
+}
+
+// FeedEntry is a single item of a Feed.
+type FeedEntry struct {
+	ID      string
+	Title   string
+	Link    string
+	Summary string
+	Author  string
+	// Updated is zero when the post has no comments, the Generated time of the feed is written instead.
+	Updated time.Time
+	// Categories are the classes, skill and type of the post.
+	Categories []string
+}
+
+// Feed is a list of recruitment posts which can be written as an Atom or JSON Feed document.
+type Feed struct {
+	ID    string
+	Title string
+	// Link is the html page the feed mirrors.
+	Link string
+	// FeedURL is the address the feed is served from, it is optional.
+	FeedURL string
+	// Generated is used as the updated time of a feed and entries without one, defaults to the current time.
+	Generated time.Time
+	Entries   []FeedEntry
+}
+
+// Updated returns the most recent entry update.
+func (f Feed) Updated() time.Time {
+	var updated time.Time
+
+	for _, entry := range f.Entries {
+		if entry.Updated.After(updated) {
+			updated = entry.Updated
+		}
+	}
+
+	if updated.IsZero() {
+		updated = f.generated()
+	}
+
+	return updated
+}
+
+func (f Feed) generated() time.Time {
+	if f.Generated.IsZero() {
+		return time.Now()
+	}
+
+	return f.Generated
+}
+
+// entryUpdated returns the updated time written for an entry, falling back to the generated time of the feed.
+func entryUpdated(entry FeedEntry, generated time.Time) string {
+	if entry.Updated.IsZero() {
+		return generated.UTC().Format(time.RFC3339)
+	}
+
+	return entry.Updated.UTC().Format(time.RFC3339)
+}
+
+// commentTime converts the time of the last comment of a post, posts without comments have no known update time.
+func commentTime(last int) time.Time {
+	if last == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(last), 0).UTC()
+}
+
+func postCategories(classes PlayerClasses, skill string, teamType string) []string {
+	var categories []string
+
+	for _, value := range append(append([]string{}, classes...), skill, teamType) {
+		if value != "" && !slices.Contains(categories, value) {
+			categories = append(categories, value)
+		}
+	}
+
+	return categories
+}
+
+func postSummary(classes PlayerClasses, skill string, teamType string) string {
+	return fmt.Sprintf("Classes: %s\nSkill: %s\nType: %s", strings.Join(classes, ", "), skill, teamType)
+}
+
+func newRecruitmentFeed(kind RecruitmentKind, title string, entries []FeedEntry) Feed {
+	slices.SortStableFunc(entries, func(a, b FeedEntry) int {
+		if order := b.Updated.Compare(a.Updated); order != 0 {
+			return order
+		}
+
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return Feed{
+		ID:      fmt.Sprintf("tag:etf2l.org,2009:recruitment/%s", kind),
+		Title:   title,
+		Link:    "https://etf2l.org/recruitment/",
+		Entries: entries,
+	}
+}
+
+// NewPlayerRecruitmentFeed builds a feed of player posts, ordered by the time of their last comment, posts
+// without comments come last.
+func NewPlayerRecruitmentFeed(posts []PlayerRecruitment) Feed {
+	entries := make([]FeedEntry, 0, len(posts))
+
+	for _, post := range posts {
+		entries = append(entries, FeedEntry{
+			ID:         RecruitmentEntryID(RecruitmentPlayers, post.ID),
+			Title:      fmt.Sprintf("%s is looking for a %s team (%s)", post.Name, post.Type, strings.Join(post.Classes, ", ")),
+			Link:       post.Urls.Recruitment,
+			Summary:    postSummary(post.Classes, post.Skill, post.Type),
+			Author:     post.Name,
+			Updated:    commentTime(post.Comments.Last),
+			Categories: postCategories(post.Classes, post.Skill, post.Type),
+		})
+	}
+
+	return newRecruitmentFeed(RecruitmentPlayers, "ETF2L player recruitment", entries)
+}
+
+// NewTeamRecruitmentFeed builds a feed of team posts, ordered by the time of their last comment, posts
+// without comments come last.
+func NewTeamRecruitmentFeed(posts []TeamRecruitment) Feed {
+	entries := make([]FeedEntry, 0, len(posts))
+
+	for _, post := range posts {
+		entries = append(entries, FeedEntry{
+			ID:         RecruitmentEntryID(RecruitmentTeams, post.ID),
+			Title:      fmt.Sprintf("%s is looking for %s (%s)", post.Name, strings.Join(post.Classes, ", "), post.Type),
+			Link:       post.Urls.Recruitment,
+			Summary:    postSummary(post.Classes, post.Skill, post.Type),
+			Author:     post.Name,
+			Updated:    commentTime(post.Comments.Last),
+			Categories: postCategories(post.Classes, post.Skill, post.Type),
+		})
+	}
+
+	return newRecruitmentFeed(RecruitmentTeams, "ETF2L team recruitment", entries)
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Link       *atomLink      `xml:"link,omitempty"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Summary    string         `xml:"summary,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomPerson  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// WriteAtom writes the feed as an Atom (RFC 4287) document.
+func (f Feed) WriteAtom(writer io.Writer) error {
+	generated := f.generated()
+
+	doc := atomFeed{
+		ID:      f.ID,
+		Title:   f.Title,
+		Updated: f.Updated().UTC().Format(time.RFC3339),
+		Author:  atomPerson{Name: "ETF2L"},
+	}
+
+	if f.Link != "" {
+		doc.Links = append(doc.Links, atomLink{Href: f.Link, Rel: "alternate", Type: "text/html"})
+	}
+
+	if f.FeedURL != "" {
+		doc.Links = append(doc.Links, atomLink{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"})
+	}
+
+	for _, entry := range f.Entries {
+		atom := atomEntry{
+			ID:      entry.ID,
+			Title:   entry.Title,
+			Updated: entryUpdated(entry, generated),
+			Summary: entry.Summary,
+		}
+
+		if entry.Link != "" {
+			atom.Link = &atomLink{Href: entry.Link, Rel: "alternate", Type: "text/html"}
+		}
+
+		if entry.Author != "" {
+			atom.Author = &atomPerson{Name: entry.Author}
+		}
+
+		for _, category := range entry.Categories {
+			atom.Categories = append(atom.Categories, atomCategory{Term: category})
+		}
+
+		doc.Entries = append(doc.Entries, atom)
+	}
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return errors.Wrap(err, "Failed to write feed")
+	}
+
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(doc); err != nil {
+		return errors.Wrap(err, "Failed to encode atom feed")
+	}
+
+	return nil
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedItem struct {
+	ID           string           `json:"id"`
+	URL          string           `json:"url,omitempty"`
+	Title        string           `json:"title"`
+	ContentText  string           `json:"content_text"`
+	DateModified string           `json:"date_modified"`
+	Authors      []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags         []string         `json:"tags,omitempty"`
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url,omitempty"`
+	FeedURL     string         `json:"feed_url,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+// WriteJSONFeed writes the feed as a JSON Feed 1.1 document.
+func (f Feed) WriteJSONFeed(writer io.Writer) error {
+	generated := f.generated()
+
+	doc := jsonFeed{
+		Version:     jsonFeedVersion,
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Items:       make([]jsonFeedItem, 0, len(f.Entries)),
+	}
+
+	for _, entry := range f.Entries {
+		item := jsonFeedItem{
+			ID:           entry.ID,
+			URL:          entry.Link,
+			Title:        entry.Title,
+			ContentText:  entry.Summary,
+			DateModified: entryUpdated(entry, generated),
+			Tags:         entry.Categories,
+		}
+
+		if entry.Author != "" {
+			item.Authors = []jsonFeedAuthor{{Name: entry.Author}}
+		}
+
+		doc.Items = append(doc.Items, item)
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(doc); err != nil {
+		return errors.Wrap(err, "Failed to encode json feed")
+	}
+
+	return nil
+}
+
+// PlayerRecruitmentFeed fetches every player post matching opts and builds a feed of them.
+func (client *Client) PlayerRecruitmentFeed(ctx context.Context, httpClient HTTPExecutor, opts RecruitmentOpts) (Feed, error) {
+	opts.Recursive = true
+
+	posts, err := client.PlayerRecruitment(ctx, httpClient, opts)
+	if err != nil {
+		return Feed{}, err
+	}
+
+	return NewPlayerRecruitmentFeed(posts), nil
+}
+
+// TeamRecruitmentFeed fetches every team post matching opts and builds a feed of them.
+func (client *Client) TeamRecruitmentFeed(ctx context.Context, httpClient HTTPExecutor, opts RecruitmentOpts) (Feed, error) {
+	opts.Recursive = true
+
+	posts, err := client.TeamRecruitment(ctx, httpClient, opts)
+	if err != nil {
+		return Feed{}, err
+	}
+
+	return NewTeamRecruitmentFeed(posts), nil
+}
@@ -0,0 +1,196 @@
+package etf2l
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// MeetingMap is the result of a single map of a meeting, scores are from the perspective of the head-to-head.
+type MeetingMap struct {
+	Map       string
+	ScoreA    int
+	ScoreB    int
+	GoldenCap bool
+	Outcome   MatchOutcome
+}
+
+// Meeting is a single match played between the two teams of a head-to-head.
+type Meeting struct {
+	MatchID     int
+	Time        time.Time
+	Competition MatchCompetition
+	Round       string
+	ScoreA      int
+	ScoreB      int
+	Outcome     MatchOutcome
+	DefaultWin  bool
+	Maps        []MeetingMap
+}
+
+// HeadToHeadMap is the record of both teams on a single map.
+type HeadToHeadMap struct {
+	Played     int
+	WinsA      int
+	WinsB      int
+	Draws      int
+	GoldenCaps int
+}
+
+// HeadToHead is the history between two teams, with all records from the perspective of TeamA.
+type HeadToHead struct {
+	TeamA int
+	TeamB int
+	// Meetings is the timeline of matches between the teams, oldest first.
+	Meetings     []Meeting
+	Record       CareerRecord
+	Maps         map[string]HeadToHeadMap
+	GoldenCapsA  int
+	GoldenCapsB  int
+	DefaultWinsA int
+	DefaultWinsB int
+}
+
+// NewHeadToHead builds the head-to-head record for two teams. Matches which were not played between the two
+// teams, or have not been played yet, are ignored.
+func NewHeadToHead(teamA int, teamB int, matches []MatchDetails) HeadToHead {
+	h2h := HeadToHead{TeamA: teamA, TeamB: teamB, Maps: map[string]HeadToHeadMap{}}
+
+	for _, match := range matches {
+		if match.Submitted == 0 && !match.Defaultwin {
+			continue
+		}
+
+		var swapped bool
+
+		switch {
+		case match.Clan1.ID == teamA && match.Clan2.ID == teamB:
+		case match.Clan1.ID == teamB && match.Clan2.ID == teamA:
+			swapped = true
+		default:
+			continue
+		}
+
+		h2h.addMeeting(newMeeting(match, swapped))
+	}
+
+	slices.SortStableFunc(h2h.Meetings, func(a, b Meeting) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	return h2h
+}
+
+func newMeeting(match MatchDetails, swapped bool) Meeting {
+	orient := func(clan1 int, clan2 int) (int, int) {
+		if swapped {
+			return clan2, clan1
+		}
+
+		return clan1, clan2
+	}
+
+	scoreA, scoreB := orient(match.R1, match.R2)
+	meeting := Meeting{
+		MatchID:     match.ID,
+		Time:        time.Unix(int64(match.Time), 0),
+		Competition: match.Competition,
+		Round:       match.Round,
+		ScoreA:      scoreA,
+		ScoreB:      scoreB,
+		Outcome:     outcome(scoreA, scoreB),
+		DefaultWin:  match.Defaultwin,
+	}
+
+	mapResults := slices.Clone(match.MapResults)
+	slices.SortStableFunc(mapResults, func(a, b MatchMapResult) int {
+		return a.MatchOrder - b.MatchOrder
+	})
+
+	for _, result := range mapResults {
+		mapA, mapB := orient(result.Clan1, result.Clan2)
+		meeting.Maps = append(meeting.Maps, MeetingMap{
+			Map:       result.Map,
+			ScoreA:    mapA,
+			ScoreB:    mapB,
+			GoldenCap: result.GoldenCap,
+			Outcome:   outcome(mapA, mapB),
+		})
+	}
+
+	return meeting
+}
+
+func (h *HeadToHead) addMeeting(meeting Meeting) {
+	h.Meetings = append(h.Meetings, meeting)
+	h.Record.add(meeting.Outcome, meeting.DefaultWin, meeting.ScoreA, meeting.ScoreB)
+
+	if meeting.DefaultWin {
+		switch meeting.Outcome {
+		case OutcomeWin:
+			h.DefaultWinsA++
+		case OutcomeLoss:
+			h.DefaultWinsB++
+		case OutcomeDraw:
+		}
+
+		return
+	}
+
+	for _, result := range meeting.Maps {
+		record := h.Maps[result.Map]
+		record.Played++
+
+		switch result.Outcome {
+		case OutcomeWin:
+			record.WinsA++
+		case OutcomeLoss:
+			record.WinsB++
+		case OutcomeDraw:
+			record.Draws++
+		}
+
+		if result.GoldenCap {
+			record.GoldenCaps++
+
+			switch result.Outcome {
+			case OutcomeWin:
+				h.GoldenCapsA++
+			case OutcomeLoss:
+				h.GoldenCapsB++
+			case OutcomeDraw:
+			}
+		}
+
+		h.Maps[result.Map] = record
+	}
+}
+
+// HeadToHead fetches every match played between two teams, including the per map results, and builds their
+// head-to-head record.
+func (client *Client) HeadToHead(ctx context.Context, httpClient HTTPExecutor, teamA int, teamB int) (HeadToHead, error) {
+	matches, _, errMatches := client.Matches(ctx, httpClient, MatchesOpts{
+		BaseOpts: BaseOpts{Recursive: true},
+		Vs:       teamA,
+	})
+	if errMatches != nil {
+		return HeadToHead{}, errMatches
+	}
+
+	var details []MatchDetails
+
+	for _, match := range matches {
+		if match.Clan1.ID != teamB && match.Clan2.ID != teamB {
+			continue
+		}
+
+		detail, errDetail := client.MatchDetails(ctx, httpClient, match.ID)
+		if errDetail != nil {
+			return HeadToHead{}, errDetail
+		}
+
+		details = append(details, *detail)
+	}
+
+	return NewHeadToHead(teamA, teamB, details), nil
+}
@@ -0,0 +1,400 @@
+package etf2l
+
+import (
+	"bufio"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const unlistedItemsKey = "unlisted_items_default_to"
+
+// DefaultWhitelistInterval is how often a WhitelistWatcher polls the whitelist listing.
+const DefaultWhitelistInterval = time.Hour
+
+// ItemWhitelist is a parsed TF2 item_whitelist file.
+type ItemWhitelist struct {
+	// UnlistedItemsDefaultTo is whether items not present in Items are allowed.
+	UnlistedItemsDefaultTo bool
+	// Items maps item names to whether they are allowed.
+	Items map[string]bool
+}
+
+// Allowed returns whether the item is usable under the whitelist.
+func (w *ItemWhitelist) Allowed(item string) bool {
+	if allowed, found := w.Items[item]; found {
+		return allowed
+	}
+
+	return w.UnlistedItemsDefaultTo
+}
+
+// AllowedItems returns the sorted names of the listed items which are allowed.
+func (w *ItemWhitelist) AllowedItems() []string {
+	var items []string
+
+	for _, item := range slices.Sorted(maps.Keys(w.Items)) {
+		if w.Items[item] {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// WriteTo writes the whitelist in the item_whitelist format with the items sorted by name, so that the output
+// only changes when the whitelist does.
+func (w *ItemWhitelist) WriteTo(writer io.Writer) (int64, error) {
+	var builder strings.Builder
+
+	builder.WriteString("\"item_whitelist\"\n{\n")
+	builder.WriteString(fmt.Sprintf("\t%q\t%q\n", unlistedItemsKey, boolValue(w.UnlistedItemsDefaultTo)))
+
+	for _, item := range slices.Sorted(maps.Keys(w.Items)) {
+		builder.WriteString(fmt.Sprintf("\t\"%s\"\t%q\n", item, boolValue(w.Items[item])))
+	}
+
+	builder.WriteString("}\n")
+
+	written, err := io.WriteString(writer, builder.String())
+
+	return int64(written), err
+}
+
+func boolValue(value bool) string {
+	if value {
+		return "1"
+	}
+
+	return "0"
+}
+
+// ParseItemWhitelist parses the KeyValues based item_whitelist format used by TF2 servers.
+func ParseItemWhitelist(reader io.Reader) (*ItemWhitelist, error) {
+	tokens, errTokens := keyValueTokens(reader)
+	if errTokens != nil {
+		return nil, errTokens
+	}
+
+	if len(tokens) < 3 || !strings.EqualFold(tokens[0].value, "item_whitelist") || tokens[1].value != "{" ||
+		tokens[1].quoted {
+		return nil, errors.New("Missing item_whitelist block")
+	}
+
+	whitelist := &ItemWhitelist{Items: map[string]bool{}}
+
+	for idx := 2; idx < len(tokens); idx += 2 {
+		key := tokens[idx]
+		if !key.quoted && key.value == "}" {
+			if idx != len(tokens)-1 {
+				return nil, errors.Errorf("Unexpected content after item_whitelist block on line %d", key.line)
+			}
+
+			return whitelist, nil
+		}
+
+		if idx+1 >= len(tokens) {
+			return nil, errors.Errorf("Missing value for %s on line %d", key.value, key.line)
+		}
+
+		value := tokens[idx+1]
+		if !value.quoted && (value.value == "{" || value.value == "}") {
+			return nil, errors.Errorf("Unexpected %s on line %d", value.value, value.line)
+		}
+
+		allowed := value.value != "" && value.value != "0"
+
+		if strings.EqualFold(key.value, unlistedItemsKey) {
+			whitelist.UnlistedItemsDefaultTo = allowed
+
+			continue
+		}
+
+		whitelist.Items[key.value] = allowed
+	}
+
+	return nil, errors.New("Unterminated item_whitelist block")
+}
+
+type keyValueToken struct {
+	value  string
+	quoted bool
+	line   int
+}
+
+// keyValueTokens splits KeyValues text into quoted strings, bare words and braces, skipping // comments.
+func keyValueTokens(reader io.Reader) ([]keyValueToken, error) {
+	var (
+		tokens  []keyValueToken
+		scanner = bufio.NewScanner(reader)
+		line    int
+	)
+
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line++
+		text := []rune(scanner.Text())
+
+		for pos := 0; pos < len(text); {
+			switch char := text[pos]; {
+			case char == ' ' || char == '\t' || char == '\r' || char == '\ufeff':
+				pos++
+			case char == '/' && pos+1 < len(text) && text[pos+1] == '/':
+				pos = len(text)
+			case char == '{' || char == '}':
+				tokens = append(tokens, keyValueToken{value: string(char), line: line})
+				pos++
+			case char == '"':
+				var value strings.Builder
+
+				pos++
+				for ; pos < len(text) && text[pos] != '"'; pos++ {
+					if text[pos] == '\\' && pos+1 < len(text) && text[pos+1] == '"' {
+						pos++
+					}
+
+					value.WriteRune(text[pos])
+				}
+
+				if pos >= len(text) {
+					return nil, errors.Errorf("Unterminated string on line %d", line)
+				}
+
+				pos++
+				tokens = append(tokens, keyValueToken{value: value.String(), quoted: true, line: line})
+			default:
+				start := pos
+				for pos < len(text) && !strings.ContainsRune(" \t\r\"{}", text[pos]) {
+					pos++
+				}
+
+				tokens = append(tokens, keyValueToken{value: string(text[start:pos]), line: line})
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "Failed to read whitelist")
+	}
+
+	return tokens, nil
+}
+
+// WhitelistDiff lists the items whose availability changed between two versions of a whitelist.
+type WhitelistDiff struct {
+	// Added items are allowed in the new version but were not in the old one.
+	Added []string
+	// Removed items were allowed in the old version but are not in the new one.
+	Removed []string
+	// DefaultChanged is set when unlisted_items_default_to changed, which affects every item not listed.
+	DefaultChanged bool
+}
+
+// Empty returns true when the versions allow the same items.
+func (d WhitelistDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && !d.DefaultChanged
+}
+
+// DiffItemWhitelists compares the items listed in either version. A nil whitelist is treated as allowing nothing.
+func DiffItemWhitelists(previous *ItemWhitelist, current *ItemWhitelist) WhitelistDiff {
+	if previous == nil {
+		previous = &ItemWhitelist{}
+	}
+
+	if current == nil {
+		current = &ItemWhitelist{}
+	}
+
+	diff := WhitelistDiff{DefaultChanged: previous.UnlistedItemsDefaultTo != current.UnlistedItemsDefaultTo}
+	items := maps.Clone(previous.Items)
+
+	if items == nil {
+		items = map[string]bool{}
+	}
+
+	maps.Copy(items, current.Items)
+
+	for _, item := range slices.Sorted(maps.Keys(items)) {
+		before, after := previous.Allowed(item), current.Allowed(item)
+
+		switch {
+		case after && !before:
+			diff.Added = append(diff.Added, item)
+		case before && !after:
+			diff.Removed = append(diff.Removed, item)
+		}
+	}
+
+	return diff
+}
+
+// ItemWhitelist downloads and parses the whitelist file referenced by a Whitelist listing.
+func (client *Client) ItemWhitelist(ctx context.Context, httpClient HTTPExecutor, whitelist Whitelist) (*ItemWhitelist, error) {
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodGet, whitelist.URL, nil)
+	if errReq != nil {
+		return nil, errors.Wrap(errReq, "Failed to create request")
+	}
+
+	resp, errResp := client.chain(httpClient).Do(req)
+	if errResp != nil {
+		return nil, errors.Wrap(errResp, "Failed to download whitelist")
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("Invalid status code: %s", resp.Status)
+	}
+
+	parsed, errParse := ParseItemWhitelist(resp.Body)
+	if errParse != nil {
+		return nil, errors.Wrapf(errParse, "Failed to parse %s", whitelist.Filename)
+	}
+
+	return parsed, nil
+}
+
+// WhitelistChange describes a whitelist whose LastChange moved since it was last seen.
+type WhitelistChange struct {
+	// Name is the key of the whitelist in the listing.
+	Name string
+	// Previous is the listing entry from the last check, it is the zero value for newly seen whitelists.
+	Previous Whitelist
+	Current  Whitelist
+	Items    *ItemWhitelist
+	// Diff is relative to the previously downloaded version, or to an empty whitelist for newly seen ones.
+	Diff WhitelistDiff
+}
+
+// WhitelistWatcher polls the whitelist listing and downloads whitelists when their LastChange moves.
+type WhitelistWatcher struct {
+	// Interval between polls made by Watch.
+	Interval time.Duration
+	// OnError is called by Watch with the errors of a failed check, they are logged with slog when it is nil.
+	OnError    func(err error)
+	client     *Client
+	httpClient HTTPExecutor
+	seen       map[string]Whitelist
+	items      map[string]*ItemWhitelist
+}
+
+func NewWhitelistWatcher(client *Client, httpClient HTTPExecutor) *WhitelistWatcher {
+	return &WhitelistWatcher{
+		Interval:   DefaultWhitelistInterval,
+		client:     client,
+		httpClient: httpClient,
+		seen:       map[string]Whitelist{},
+		items:      map[string]*ItemWhitelist{},
+	}
+}
+
+// Check polls the listing once and returns the whitelists which changed, sorted by name. On the first call every
+// whitelist is reported. A whitelist which fails to download does not stop the others from being checked, the
+// errors of every failed download are returned along with the changes and they are retried on the next check.
+func (w *WhitelistWatcher) Check(ctx context.Context) ([]WhitelistChange, error) {
+	listing, errListing := w.client.Whitelists(ctx, w.httpClient)
+	if errListing != nil {
+		return nil, errListing
+	}
+
+	var (
+		changes []WhitelistChange
+		errs    []error
+	)
+
+	for _, name := range slices.Sorted(maps.Keys(listing)) {
+		current := listing[name]
+
+		previous, found := w.seen[name]
+		if found && previous.LastChange == current.LastChange {
+			continue
+		}
+
+		items, errItems := w.client.ItemWhitelist(ctx, w.httpClient, current)
+		if errItems != nil {
+			errs = append(errs, errors.Wrapf(errItems, "Failed to fetch whitelist %s", name))
+
+			continue
+		}
+
+		changes = append(changes, WhitelistChange{
+			Name:     name,
+			Previous: previous,
+			Current:  current,
+			Items:    items,
+			Diff:     DiffItemWhitelists(w.items[name], items),
+		})
+
+		w.seen[name] = current
+		w.items[name] = items
+	}
+
+	for name := range w.seen {
+		if _, found := listing[name]; !found {
+			delete(w.seen, name)
+			delete(w.items, name)
+		}
+	}
+
+	return changes, stderrors.Join(errs...)
+}
+
+// Watch calls Check immediately and then every Interval, passing each change to onChange. Errors from Check are
+// passed to OnError and the whitelists are checked again on the next tick. It returns when ctx is cancelled or
+// onChange returns an error.
+func (w *WhitelistWatcher) Watch(ctx context.Context, onChange func(WhitelistChange) error) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWhitelistInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		changes, errCheck := w.Check(ctx)
+
+		for _, change := range changes {
+			if err := onChange(change); err != nil {
+				return err
+			}
+		}
+
+		// Failures caused by the cancellation are not reported, Watch returns below.
+		if errCheck != nil && ctx.Err() == nil {
+			w.reportError(ctx, errCheck)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *WhitelistWatcher) reportError(ctx context.Context, err error) {
+	if w.OnError != nil {
+		w.OnError(err)
+
+		return
+	}
+
+	slog.ErrorContext(ctx, "Failed to check whitelists", slog.String("error", err.Error()))
+}
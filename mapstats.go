@@ -0,0 +1,224 @@
+package etf2l
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// TeamMapRecord is the record of a single team on a map.
+type TeamMapRecord struct {
+	TeamID int
+	Name   string
+	Played int
+	Won    int
+	Lost   int
+	Drawn  int
+}
+
+// WinRate returns the fraction of the maps played that were won.
+func (r TeamMapRecord) WinRate() float64 {
+	return rate(r.Won, r.Played)
+}
+
+// MapStats aggregates the results of every time a map was played.
+type MapStats struct {
+	Map    string
+	InPool bool
+	// Scheduled counts the matches that listed the map, whether or not a result was recorded for it.
+	Scheduled  int
+	Played     int
+	Clan1Wins  int
+	Clan2Wins  int
+	Draws      int
+	GoldenCaps int
+	Teams      map[int]*TeamMapRecord
+}
+
+// Clan1WinRate returns the fraction of the map results won by the first listed clan, which starts on blu.
+func (s MapStats) Clan1WinRate() float64 {
+	return rate(s.Clan1Wins, s.Played)
+}
+
+// GoldenCapRate returns the fraction of the map results decided by a golden cap.
+func (s MapStats) GoldenCapRate() float64 {
+	return rate(s.GoldenCaps, s.Played)
+}
+
+// TeamRecords returns the team records for the map, best win rate first.
+func (s MapStats) TeamRecords() []TeamMapRecord {
+	records := make([]TeamMapRecord, 0, len(s.Teams))
+	for _, record := range s.Teams {
+		records = append(records, *record)
+	}
+
+	slices.SortFunc(records, func(a, b TeamMapRecord) int {
+		switch {
+		case a.WinRate() > b.WinRate():
+			return -1
+		case a.WinRate() < b.WinRate():
+			return 1
+		case a.Played != b.Played:
+			return b.Played - a.Played
+		default:
+			return a.TeamID - b.TeamID
+		}
+	})
+
+	return records
+}
+
+func rate(count int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(count) / float64(total)
+}
+
+// MapReport holds the map statistics of one or more competitions.
+type MapReport struct {
+	// Maps are ordered by the number of times played, most played first.
+	Maps []MapStats
+	// Unplayed lists the maps of the pool which were never played.
+	Unplayed []string
+}
+
+// Map returns the statistics of a single map.
+func (r MapReport) Map(name string) (MapStats, bool) {
+	idx := slices.IndexFunc(r.Maps, func(stats MapStats) bool { return strings.EqualFold(stats.Map, name) })
+	if idx < 0 {
+		return MapStats{}, false
+	}
+
+	return r.Maps[idx], true
+}
+
+// NewMapReport aggregates the maps of the provided matches. The pool, which may span multiple competitions of
+// a season, is used to flag maps played outside of it and to find maps which were never played. Default wins
+// are ignored.
+func NewMapReport(pool []string, matches []MatchDetails) MapReport {
+	stats := map[string]*MapStats{}
+
+	get := func(name string) *MapStats {
+		if _, found := stats[name]; !found {
+			stats[name] = &MapStats{
+				Map:    name,
+				InPool: slices.Contains(pool, name),
+				Teams:  map[int]*TeamMapRecord{},
+			}
+		}
+
+		return stats[name]
+	}
+
+	team := func(mapStats *MapStats, clan MatchClan) *TeamMapRecord {
+		if _, found := mapStats.Teams[clan.ID]; !found {
+			mapStats.Teams[clan.ID] = &TeamMapRecord{TeamID: clan.ID, Name: clan.Name}
+		}
+
+		return mapStats.Teams[clan.ID]
+	}
+
+	for _, match := range matches {
+		if match.Defaultwin {
+			continue
+		}
+
+		for _, name := range match.Maps {
+			get(name).Scheduled++
+		}
+
+		for _, result := range match.MapResults {
+			mapStats := get(result.Map)
+			mapStats.Played++
+
+			if result.GoldenCap {
+				mapStats.GoldenCaps++
+			}
+
+			clan1, clan2 := team(mapStats, match.Clan1), team(mapStats, match.Clan2)
+			clan1.Played++
+			clan2.Played++
+
+			switch outcome(result.Clan1, result.Clan2) {
+			case OutcomeWin:
+				mapStats.Clan1Wins++
+				clan1.Won++
+				clan2.Lost++
+			case OutcomeLoss:
+				mapStats.Clan2Wins++
+				clan1.Lost++
+				clan2.Won++
+			case OutcomeDraw:
+				mapStats.Draws++
+				clan1.Drawn++
+				clan2.Drawn++
+			}
+		}
+	}
+
+	var report MapReport
+
+	for _, name := range pool {
+		if mapStats, found := stats[name]; !found || mapStats.Played == 0 {
+			report.Unplayed = append(report.Unplayed, name)
+		}
+	}
+
+	for _, mapStats := range stats {
+		report.Maps = append(report.Maps, *mapStats)
+	}
+
+	slices.SortFunc(report.Maps, func(a, b MapStats) int {
+		if a.Played != b.Played {
+			return b.Played - a.Played
+		}
+
+		return strings.Compare(a.Map, b.Map)
+	})
+
+	return report
+}
+
+// CompetitionMapReport fetches the pool and every match of the given competitions, eg: all the divisions or
+// competitions making up a season, and builds their map statistics.
+func (client *Client) CompetitionMapReport(ctx context.Context, httpClient HTTPExecutor, competitionIDs ...int) (MapReport, error) {
+	var (
+		pool    []string
+		matches []MatchDetails
+	)
+
+	for _, competitionID := range competitionIDs {
+		competition, errCompetition := client.CompetitionDetails(ctx, httpClient, competitionID)
+		if errCompetition != nil {
+			return MapReport{}, errCompetition
+		}
+
+		for _, name := range competition.Pool {
+			if !slices.Contains(pool, name) {
+				pool = append(pool, name)
+			}
+		}
+
+		competitionMatches, errMatches := client.CompetitionMatches(ctx, httpClient, competitionID, BaseOpts{Recursive: true})
+		if errMatches != nil {
+			return MapReport{}, errMatches
+		}
+
+		for _, competitionMatch := range competitionMatches {
+			if competitionMatch.Defaultwin {
+				continue
+			}
+
+			match, errMatch := client.MatchDetails(ctx, httpClient, competitionMatch.ID)
+			if errMatch != nil {
+				return MapReport{}, errMatch
+			}
+
+			matches = append(matches, *match)
+		}
+	}
+
+	return NewMapReport(pool, matches), nil
+}
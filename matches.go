@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/google/go-querystring/query"
 	"github.com/pkg/errors"
 )
 
@@ -96,21 +97,33 @@ type MatchesOpts struct {
 	Players     []string `url:"players,omitempty"`     // A list of ETF2L user TeamID's. Returns only matches in which any of the provided players participated.
 }
 
+// Matches fetches matches from the global match list. When opts is a MatchesOpts, or a pointer to one, its filters
+// are applied.
 func (client *Client) Matches(ctx context.Context, httpClient HTTPExecutor, opts Recursive) ([]Match, int, error) {
 	var (
 		matches []Match
 		total   int
+		filters MatchesOpts
 	)
 
-	curPage := 0
+	switch typed := opts.(type) {
+	case MatchesOpts:
+		filters = typed
+	case *MatchesOpts:
+		if typed != nil {
+			filters = *typed
+		}
+	}
+
+	curPage := 1
 
 	for {
-		resp, errResp := client.MatchesPage(ctx, httpClient, curPage, 2000)
+		resp, errResp := client.MatchesPageWithOpts(ctx, httpClient, curPage, 2000, filters)
 		if errResp != nil {
 			return nil, 0, errResp
 		}
 
-		total += resp.Pager.Total
+		total = resp.Pager.Total
 
 		matches = append(matches, resp.Pager.Data...)
 
@@ -130,12 +143,28 @@ func (client *Client) Matches(ctx context.Context, httpClient HTTPExecutor, opts
 }
 
 func (client *Client) MatchesPage(ctx context.Context, httpClient HTTPExecutor, page int, limit int) (*MatchesResponse, error) {
+	return client.MatchesPageWithOpts(ctx, httpClient, page, limit, MatchesOpts{})
+}
+
+// MatchesPageWithOpts fetches a single page of matches, filtered by the query parameters of opts.
+func (client *Client) MatchesPageWithOpts(ctx context.Context, httpClient HTTPExecutor, page int, limit int, opts MatchesOpts) (*MatchesResponse, error) {
 	if limit > 2000 {
 		return nil, errors.New("limit too big. max 2000")
 	}
 
+	curPath := fmt.Sprintf("/matches?page=%d&limit=%d", page, limit)
+
+	filters, errQuery := query.Values(opts)
+	if errQuery != nil {
+		return nil, errors.Wrap(errQuery, "Failed to encode query")
+	}
+
+	if len(filters) > 0 {
+		curPath += "&" + filters.Encode()
+	}
+
 	var resp MatchesResponse
-	if err := client.call(ctx, httpClient, fmt.Sprintf("/matches?page=%d&limit=%d", page, limit), nil, &resp); err != nil {
+	if err := client.call(ctx, httpClient, curPath, nil, &resp); err != nil {
 		return nil, err
 	}
 
@@ -150,6 +179,16 @@ type MatchMapResult struct {
 	GoldenCap  bool   `json:"golden_cap"`
 }
 
+// MatchPlayer is a player who took part in a match.
+type MatchPlayer struct {
+	ID      int         `json:"id"`
+	Name    string      `json:"name"`
+	Country string      `json:"country"`
+	Steam   SteamPlayer `json:"steam"`
+	// Team is the id of the clan the player played for.
+	Team int `json:"team"`
+}
+
 type MatchDetails struct {
 	Clan1       MatchClan        `json:"clan1"`
 	Clan2       MatchClan        `json:"clan2"`
@@ -168,9 +207,9 @@ type MatchDetails struct {
 		Self string `json:"self"`
 		API  string `json:"api"`
 	} `json:"urls"`
-	Players    []interface{}    `json:"players"`
+	Players    []MatchPlayer    `json:"players"`
 	ByeWeek    bool             `json:"bye_week"`
-	Demos      []interface{}    `json:"demos"`
+	Demos      []Demo           `json:"demos"`
 	MapResults []MatchMapResult `json:"map_results"`
 }
 
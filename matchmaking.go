@@ -0,0 +1,300 @@
+package etf2l
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// OpenTier is the tier of the open division, the lowest skill level posts can be made for.
+const OpenTier = 7
+
+// DefaultShortlistSize is the number of candidates kept per team when RecruitmentMatcher.Limit is unset.
+const DefaultShortlistSize = 10
+
+// SkillTier converts a recruitment skill level or division name into a tier, 0 being the Premiership and
+// OpenTier the open division. Lower tiers are more skilled.
+func SkillTier(skill string) (int, bool) {
+	name := strings.ToLower(strings.TrimSpace(skill))
+
+	switch {
+	case strings.HasPrefix(name, "prem"):
+		return 0, true
+	case name == "high":
+		return 1, true
+	case name == "mid":
+		return 3, true
+	case name == "low":
+		return 5, true
+	case strings.HasPrefix(name, "open"):
+		return OpenTier, true
+	}
+
+	for _, prefix := range []string{"division", "div"} {
+		if rest, found := strings.CutPrefix(name, prefix); found {
+			tier, err := strconv.Atoi(strings.TrimSpace(rest))
+			if err != nil || tier < 1 || tier >= OpenTier {
+				return 0, false
+			}
+
+			return tier, true
+		}
+	}
+
+	return 0, false
+}
+
+// MatchWeights sets the contribution of each criteria to the score of a RecruitmentMatch. Scores are normalised
+// by the sum of the weights.
+type MatchWeights struct {
+	Classes float64
+	Skill   float64
+	History float64
+	Country float64
+}
+
+var DefaultMatchWeights = MatchWeights{Classes: 2, Skill: 3, History: 3, Country: 1}
+
+// RecruitingPlayer is a player recruitment post along with the details used to score it.
+type RecruitingPlayer struct {
+	Post PlayerRecruitment
+	// Country of the player, empty when unknown.
+	Country string
+	// Results of the player, used to find the divisions they have played in.
+	Results []PlayerResult
+}
+
+// RecruitingTeam is a team recruitment post along with the details used to score it.
+type RecruitingTeam struct {
+	Post TeamRecruitment
+	// TeamID parsed from the post, 0 when unknown.
+	TeamID int
+	// Country of the team, empty when unknown.
+	Country string
+}
+
+// RecruitmentMatch is a player scored against a team post.
+type RecruitmentMatch struct {
+	Player RecruitingPlayer
+	// Score between 0 and 1, higher is a better fit.
+	Score float64
+	// Classes the player offers which the team is looking for.
+	Classes []string
+	// SkillGap is the difference in tiers between the posts, positive when the player posted a higher skill.
+	SkillGap int
+	// HighestTier the player has played in the team format, -1 without any history.
+	HighestTier int
+	// Matches played in the team format, default wins excluded.
+	Matches     int
+	SameCountry bool
+}
+
+// RecruitmentShortlist is the ranked list of players matching a team post.
+type RecruitmentShortlist struct {
+	Team       RecruitingTeam
+	Candidates []RecruitmentMatch
+}
+
+// RecruitmentMatcher pairs player recruitment posts with team posts. Players are only considered for teams
+// recruiting for the same format and at least one of the classes they play.
+type RecruitmentMatcher struct {
+	Weights MatchWeights
+	// Limit is the maximum candidates per team, DefaultShortlistSize when 0.
+	Limit int
+}
+
+func NewRecruitmentMatcher() RecruitmentMatcher {
+	return RecruitmentMatcher{Weights: DefaultMatchWeights, Limit: DefaultShortlistSize}
+}
+
+// Match returns a shortlist for every team, in the order the teams were given. Candidates are ordered by
+// descending score.
+func (m RecruitmentMatcher) Match(teams []RecruitingTeam, players []RecruitingPlayer) []RecruitmentShortlist {
+	limit := m.Limit
+	if limit <= 0 {
+		limit = DefaultShortlistSize
+	}
+
+	shortlists := make([]RecruitmentShortlist, 0, len(teams))
+
+	for _, team := range teams {
+		shortlist := RecruitmentShortlist{Team: team}
+
+		for _, player := range players {
+			if match, ok := m.score(team, player); ok {
+				shortlist.Candidates = append(shortlist.Candidates, match)
+			}
+		}
+
+		slices.SortStableFunc(shortlist.Candidates, func(a, b RecruitmentMatch) int {
+			if order := cmp.Compare(b.Score, a.Score); order != 0 {
+				return order
+			}
+
+			return cmp.Compare(a.Player.Post.ID, b.Player.Post.ID)
+		})
+
+		if len(shortlist.Candidates) > limit {
+			shortlist.Candidates = shortlist.Candidates[:limit]
+		}
+
+		shortlists = append(shortlists, shortlist)
+	}
+
+	return shortlists
+}
+
+// tierScore is 1 for identical tiers, falling linearly to 0 for the widest possible gap.
+func tierScore(a int, b int) float64 {
+	return 1 - math.Abs(float64(a-b))/OpenTier
+}
+
+func (m RecruitmentMatcher) score(team RecruitingTeam, player RecruitingPlayer) (RecruitmentMatch, bool) {
+	if !strings.EqualFold(team.Post.Type, player.Post.Type) {
+		return RecruitmentMatch{}, false
+	}
+
+	match := RecruitmentMatch{Player: player, HighestTier: -1}
+
+	for _, class := range player.Post.Classes {
+		if slices.ContainsFunc(team.Post.Classes, func(wanted string) bool {
+			return strings.EqualFold(wanted, class)
+		}) {
+			match.Classes = append(match.Classes, class)
+		}
+	}
+
+	if len(match.Classes) == 0 {
+		return RecruitmentMatch{}, false
+	}
+
+	var (
+		weights              = m.Weights
+		total                = weights.Classes + weights.Skill + weights.History + weights.Country
+		score                float64
+		teamTier, teamOK     = SkillTier(team.Post.Skill)
+		playerTier, playerOK = SkillTier(player.Post.Skill)
+	)
+
+	score += weights.Classes * float64(len(match.Classes)) / float64(min(len(team.Post.Classes), len(player.Post.Classes)))
+
+	if teamOK && playerOK {
+		match.SkillGap = teamTier - playerTier
+		score += weights.Skill * tierScore(teamTier, playerTier)
+	}
+
+	for _, result := range player.Results {
+		if !strings.EqualFold(result.Competition.Type, team.Post.Type) || result.Defaultwin {
+			continue
+		}
+
+		match.Matches++
+
+		if tier, ok := SkillTier(result.Division.Name); ok && (match.HighestTier < 0 || tier < match.HighestTier) {
+			match.HighestTier = tier
+		}
+	}
+
+	if match.HighestTier >= 0 && teamOK {
+		// Experience counts fully from a full season of matches onwards.
+		experience := min(float64(match.Matches)/10, 1)
+		score += weights.History * tierScore(teamTier, match.HighestTier) * experience
+	}
+
+	if team.Country != "" && strings.EqualFold(team.Country, player.Country) {
+		match.SameCountry = true
+		score += weights.Country
+	}
+
+	if total > 0 {
+		match.Score = score / total
+	}
+
+	return match, true
+}
+
+// teamIDFromURL extracts the team id from a team url such as https://etf2l.org/teams/3000/.
+func teamIDFromURL(teamURL string) int {
+	parsed, errParse := url.Parse(teamURL)
+	if errParse != nil {
+		return 0
+	}
+
+	teamID, errID := strconv.Atoi(path.Base(strings.TrimSuffix(parsed.Path, "/")))
+	if errID != nil {
+		return 0
+	}
+
+	return teamID
+}
+
+// RecruitmentShortlists fetches the player and team recruitment posts matching opts and ranks the players for
+// each team. Player profiles and results are fetched to find the country and history of each player, posts
+// whose player or team no longer exists are matched without those details.
+func (client *Client) RecruitmentShortlists(ctx context.Context, httpClient HTTPExecutor, opts RecruitmentOpts, matcher RecruitmentMatcher) ([]RecruitmentShortlist, error) {
+	playerPosts, errPlayers := client.PlayerRecruitment(ctx, httpClient, opts)
+	if errPlayers != nil {
+		return nil, errPlayers
+	}
+
+	teamPosts, errTeams := client.TeamRecruitment(ctx, httpClient, opts)
+	if errTeams != nil {
+		return nil, errTeams
+	}
+
+	teams := make([]RecruitingTeam, 0, len(teamPosts))
+
+	for _, post := range teamPosts {
+		team := RecruitingTeam{Post: post, TeamID: teamIDFromURL(post.Urls.Team)}
+
+		if team.TeamID > 0 {
+			details, errTeam := client.Team(ctx, httpClient, team.TeamID)
+			if errTeam != nil && !errors.Is(errTeam, ErrNotFound) {
+				return nil, errTeam
+			}
+
+			if details != nil {
+				team.Country = details.Country
+			}
+		}
+
+		teams = append(teams, team)
+	}
+
+	players := make([]RecruitingPlayer, 0, len(playerPosts))
+
+	for _, post := range playerPosts {
+		player := RecruitingPlayer{Post: post}
+
+		if post.Steam.ID64.Valid() {
+			steamID := post.Steam.ID64.String()
+
+			profile, errProfile := client.Player(ctx, httpClient, steamID)
+			if errProfile != nil && !errors.Is(errProfile, ErrNotFound) {
+				return nil, errProfile
+			}
+
+			if profile != nil {
+				player.Country = profile.Country
+
+				results, errResults := client.PlayerResults(ctx, httpClient, steamID, BaseOpts{Recursive: true})
+				if errResults != nil && !errors.Is(errResults, ErrNotFound) {
+					return nil, errResults
+				}
+
+				player.Results = results
+			}
+		}
+
+		players = append(players, player)
+	}
+
+	return matcher.Match(teams, players), nil
+}
@@ -0,0 +1,322 @@
+package etf2l
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware wraps an HTTPExecutor with additional behaviour, in the same spirit as a http.RoundTripper
+// wrapping another. Implementations must call the next executor to actually perform the request.
+type Middleware func(next HTTPExecutor) HTTPExecutor
+
+// HTTPExecutorFunc adapts an ordinary function to the HTTPExecutor interface.
+type HTTPExecutorFunc func(req *http.Request) (*http.Response, error)
+
+func (f HTTPExecutorFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// chain wraps the executor with all configured middleware.
+func (client *Client) chain(httpClient HTTPExecutor) HTTPExecutor {
+	executor := httpClient
+	for _, middleware := range slices.Backward(client.middleware) {
+		executor = middleware(executor)
+	}
+
+	return executor
+}
+
+// Endpoint returns a low cardinality name for the api endpoint being requested, suitable for use as a metric
+// label or span name. Path segments containing ids, such as /team/123/results, are replaced with {id}.
+func Endpoint(req *http.Request) string {
+	return endpointName(req.URL.Path)
+}
+
+func endpointName(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+
+	segments := strings.Split(path, "/")
+	for idx, segment := range segments {
+		if strings.ContainsAny(segment, "0123456789") {
+			segments[idx] = "{id}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+type retryKey struct{}
+
+// withRetry marks the requests made with ctx as retries following the given number of failed requests.
+func withRetry(ctx context.Context, retry int) context.Context {
+	return context.WithValue(ctx, retryKey{}, retry)
+}
+
+// Retry returns the number of failed requests which directly preceded req, 0 for requests which are not a retry.
+// Bans skips pages failing with a 500 status, the requests following the failures are reported as retries.
+func Retry(req *http.Request) int {
+	retry, _ := req.Context().Value(retryKey{}).(int)
+
+	return retry
+}
+
+// LoggingMiddleware logs each request along with its outcome and duration using the provided logger.
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return func(next HTTPExecutor) HTTPExecutor {
+		return HTTPExecutorFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.Do(req)
+			attrs := []any{
+				slog.String("method", req.Method),
+				slog.String("url", req.URL.String()),
+				slog.String("endpoint", Endpoint(req)),
+				slog.Duration("duration", time.Since(start)),
+				slog.Int("retry", Retry(req)),
+			}
+
+			if err != nil {
+				logger.ErrorContext(req.Context(), "ETF2L request failed", append(attrs, slog.String("error", err.Error()))...)
+
+				return resp, err
+			}
+
+			attrs = append(attrs, slog.Int("status", resp.StatusCode))
+
+			if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode != http.StatusNotFound {
+				logger.WarnContext(req.Context(), "ETF2L request returned error status", attrs...)
+			} else {
+				logger.DebugContext(req.Context(), "ETF2L request", attrs...)
+			}
+
+			return resp, nil
+		})
+	}
+}
+
+// TracingMiddleware starts a client span for each request using the provided OpenTelemetry tracer.
+func TracingMiddleware(tracer trace.Tracer) Middleware {
+	return func(next HTTPExecutor) HTTPExecutor {
+		return HTTPExecutorFunc(func(req *http.Request) (*http.Response, error) {
+			endpoint := Endpoint(req)
+
+			ctx, span := tracer.Start(req.Context(), req.Method+" "+endpoint,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("http.request.method", req.Method),
+					attribute.String("url.full", req.URL.String()),
+					attribute.String("http.route", endpoint),
+				))
+			defer span.End()
+
+			if retry := Retry(req); retry > 0 {
+				span.SetAttributes(attribute.Int("http.request.resend_count", retry))
+			}
+
+			resp, err := next.Do(req.WithContext(ctx))
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+
+				return resp, err
+			}
+
+			span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
+			if resp.StatusCode >= http.StatusBadRequest {
+				span.SetStatus(codes.Error, resp.Status)
+			}
+
+			return resp, nil
+		})
+	}
+}
+
+// DefaultLatencyBuckets are the histogram bucket upper bounds, in seconds, used by NewMetrics.
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+type requestKey struct {
+	endpoint string
+	code     string
+}
+
+type latencyHistogram struct {
+	counts []uint64 // Per bucket, non-cumulative.
+	sum    float64
+	count  uint64
+}
+
+// Metrics collects request and retry counters and latency histograms per endpoint. It has no dependency on the prometheus
+// client libraries, instead it renders the standard text exposition format via WritePrometheus or ServeHTTP so
+// that it can be scraped directly or exposed alongside an existing registry.
+type Metrics struct {
+	mu        sync.Mutex
+	namespace string
+	buckets   []float64
+	requests  map[requestKey]uint64
+	retries   map[string]uint64
+	latency   map[string]*latencyHistogram
+}
+
+// NewMetrics creates a metrics collector whose metric names are prefixed with namespace, typically "etf2l". When
+// no buckets are provided DefaultLatencyBuckets are used.
+func NewMetrics(namespace string, buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+
+	sorted := slices.Clone(buckets)
+	slices.Sort(sorted)
+
+	return &Metrics{
+		namespace: namespace,
+		buckets:   sorted,
+		requests:  map[requestKey]uint64{},
+		retries:   map[string]uint64{},
+		latency:   map[string]*latencyHistogram{},
+	}
+}
+
+// Middleware returns a Middleware which records every request passing through it.
+func (m *Metrics) Middleware() Middleware {
+	return func(next HTTPExecutor) HTTPExecutor {
+		return HTTPExecutorFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.Do(req)
+
+			code := "error"
+			if err == nil {
+				code = fmt.Sprintf("%d", resp.StatusCode)
+			}
+
+			m.observe(Endpoint(req), code, Retry(req) > 0, time.Since(start))
+
+			return resp, err
+		})
+	}
+}
+
+func (m *Metrics) observe(endpoint string, code string, retry bool, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestKey{endpoint: endpoint, code: code}]++
+
+	if retry {
+		m.retries[endpoint]++
+	}
+
+	hist, found := m.latency[endpoint]
+	if !found {
+		hist = &latencyHistogram{counts: make([]uint64, len(m.buckets))}
+		m.latency[endpoint] = hist
+	}
+
+	seconds := duration.Seconds()
+	hist.sum += seconds
+	hist.count++
+
+	for idx, upper := range m.buckets {
+		if seconds <= upper {
+			hist.counts[idx]++
+
+			break
+		}
+	}
+}
+
+func (m *Metrics) name(metric string) string {
+	if m.namespace == "" {
+		return metric
+	}
+
+	return m.namespace + "_" + metric
+}
+
+// WritePrometheus writes all collected metrics using the prometheus text exposition format.
+func (m *Metrics) WritePrometheus(writer io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var out strings.Builder
+
+	requestsName := m.name("requests_total")
+	fmt.Fprintf(&out, "# HELP %s Total number of requests made to the ETF2L api.\n", requestsName)
+	fmt.Fprintf(&out, "# TYPE %s counter\n", requestsName)
+
+	keys := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].endpoint == keys[j].endpoint {
+			return keys[i].code < keys[j].code
+		}
+
+		return keys[i].endpoint < keys[j].endpoint
+	})
+
+	for _, key := range keys {
+		fmt.Fprintf(&out, "%s{endpoint=%q,code=%q} %d\n", requestsName, key.endpoint, key.code, m.requests[key])
+	}
+
+	retriesName := m.name("retries_total")
+	fmt.Fprintf(&out, "# HELP %s Total number of requests made to the ETF2L api retrying a failed request.\n", retriesName)
+	fmt.Fprintf(&out, "# TYPE %s counter\n", retriesName)
+
+	for _, endpoint := range slices.Sorted(maps.Keys(m.retries)) {
+		fmt.Fprintf(&out, "%s{endpoint=%q} %d\n", retriesName, endpoint, m.retries[endpoint])
+	}
+
+	latencyName := m.name("request_duration_seconds")
+	fmt.Fprintf(&out, "# HELP %s Latency of requests made to the ETF2L api.\n", latencyName)
+	fmt.Fprintf(&out, "# TYPE %s histogram\n", latencyName)
+
+	endpoints := make([]string, 0, len(m.latency))
+	for endpoint := range m.latency {
+		endpoints = append(endpoints, endpoint)
+	}
+
+	slices.Sort(endpoints)
+
+	for _, endpoint := range endpoints {
+		hist := m.latency[endpoint]
+
+		var cumulative uint64
+
+		for idx, upper := range m.buckets {
+			cumulative += hist.counts[idx]
+			fmt.Fprintf(&out, "%s_bucket{endpoint=%q,le=\"%g\"} %d\n", latencyName, endpoint, upper, cumulative)
+		}
+
+		fmt.Fprintf(&out, "%s_bucket{endpoint=%q,le=\"+Inf\"} %d\n", latencyName, endpoint, hist.count)
+		fmt.Fprintf(&out, "%s_sum{endpoint=%q} %g\n", latencyName, endpoint, hist.sum)
+		fmt.Fprintf(&out, "%s_count{endpoint=%q} %d\n", latencyName, endpoint, hist.count)
+	}
+
+	_, err := io.WriteString(writer, out.String())
+
+	return err
+}
+
+// ServeHTTP exposes the metrics so they can be scraped directly by prometheus.
+func (m *Metrics) ServeHTTP(writer http.ResponseWriter, _ *http.Request) {
+	writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	if err := m.WritePrometheus(writer); err != nil {
+		slog.Error("Failed to write metrics", slog.String("error", err.Error()))
+	}
+}
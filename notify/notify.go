@@ -0,0 +1,357 @@
+// Package notify posts ETF2L events to Discord webhooks as embeds rendered from configurable templates.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/leighmacdonald/etf2l"
+	"github.com/pkg/errors"
+)
+
+type EventKind string
+
+const (
+	EventBan      EventKind = "ban"
+	EventResult   EventKind = "result"
+	EventReminder EventKind = "reminder"
+	EventTransfer EventKind = "transfer"
+)
+
+// Discord rejects embeds exceeding these lengths, longer values are truncated.
+const (
+	maxTitle       = 256
+	maxDescription = 4096
+	maxFieldName   = 256
+	maxFieldValue  = 1024
+)
+
+// FieldTemplate renders a single embed field, fields which render to an empty value are left out.
+type FieldTemplate struct {
+	Name   string
+	Value  string
+	Inline bool
+}
+
+// Template describes the embed of an event. Title, Description, URL and the fields are text/template strings
+// executed with a pointer to the event: etf2l.Ban, etf2l.Match or etf2l.TeamTransfer. Besides the builtin
+// functions templates can use join, unix to convert a timestamp into a time.Time and discordTime to format a
+// timestamp with a Discord style such as "R" (relative) or "f" (date and time).
+type Template struct {
+	Title       string
+	Description string
+	URL         string
+	Color       int
+	Fields      []FieldTemplate
+}
+
+// Templates holds the template used for each event.
+type Templates struct {
+	Ban      Template
+	Result   Template
+	Reminder Template
+	Transfer Template
+}
+
+// DefaultTemplates returns the templates used when no custom ones are configured.
+func DefaultTemplates() Templates {
+	return Templates{
+		Ban: Template{
+			Title:       "New ban: {{.Name}}",
+			Description: "{{.Reason}}",
+			URL:         "{{.Profile}}",
+			Color:       0xe74c3c,
+			Fields: []FieldTemplate{
+				{Name: "Steam ID", Value: "{{.Steamid64}}", Inline: true},
+				{Name: "Start", Value: "{{if .Start}}{{discordTime .Start \"f\"}}{{end}}", Inline: true},
+				{Name: "End", Value: "{{if .End}}{{discordTime .End \"f\"}}{{end}}", Inline: true},
+			},
+		},
+		Result: Template{
+			Title:       "{{.Clan1.Name}} {{.R1}} - {{.R2}} {{.Clan2.Name}}",
+			Description: "{{.Competition.Name}}{{with .Division.Name}} · {{.}}{{end}}{{with .Round}} · {{.}}{{end}}{{if .Defaultwin}}\nDefault win{{end}}",
+			URL:         "{{.Urls.Self}}",
+			Color:       0x2ecc71,
+			Fields: []FieldTemplate{
+				{Name: "Maps", Value: "{{join .Maps \", \"}}"},
+			},
+		},
+		Reminder: Template{
+			Title:       "{{.Clan1.Name}} vs {{.Clan2.Name}}",
+			Description: "Starts {{discordTime .Time \"R\"}}, {{discordTime .Time \"f\"}}",
+			URL:         "{{.Urls.Self}}",
+			Color:       0x3498db,
+			Fields: []FieldTemplate{
+				{Name: "Competition", Value: "{{.Competition.Name}}{{with .Division.Name}} · {{.}}{{end}}", Inline: true},
+				{Name: "Round", Value: "{{.Round}}", Inline: true},
+				{Name: "Maps", Value: "{{join .Maps \", \"}}"},
+			},
+		},
+		Transfer: Template{
+			Title:       "{{.Who.Name}} {{.Type}} {{.Team.Name}}",
+			Description: "{{with .By.Name}}By {{.}}{{end}}",
+			URL:         "{{.Team.URL}}",
+			Color:       0xf39c12,
+			Fields: []FieldTemplate{
+				{Name: "Player", Value: "{{with .Who.URL}}[{{$.Who.Name}}]({{.}}){{else}}{{.Who.Name}}{{end}}", Inline: true},
+				{Name: "Country", Value: "{{.Who.Country}}", Inline: true},
+			},
+		},
+	}
+}
+
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+	"unix": func(timestamp int) time.Time {
+		return time.Unix(int64(timestamp), 0).UTC()
+	},
+	"discordTime": func(timestamp int, style string) string {
+		return fmt.Sprintf("<t:%d:%s>", timestamp, style)
+	},
+}
+
+type compiledField struct {
+	name   *template.Template
+	value  *template.Template
+	inline bool
+}
+
+type compiledTemplate struct {
+	title       *template.Template
+	description *template.Template
+	url         *template.Template
+	color       int
+	fields      []compiledField
+}
+
+func parse(kind EventKind, name string, text string) (*template.Template, error) {
+	parsed, err := template.New(string(kind) + "." + name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid %s %s template", kind, name)
+	}
+
+	return parsed, nil
+}
+
+func compile(kind EventKind, tmpl Template) (compiledTemplate, error) {
+	var (
+		compiled = compiledTemplate{color: tmpl.Color}
+		err      error
+	)
+
+	if compiled.title, err = parse(kind, "title", tmpl.Title); err != nil {
+		return compiled, err
+	}
+
+	if compiled.description, err = parse(kind, "description", tmpl.Description); err != nil {
+		return compiled, err
+	}
+
+	if compiled.url, err = parse(kind, "url", tmpl.URL); err != nil {
+		return compiled, err
+	}
+
+	for idx, field := range tmpl.Fields {
+		name, errName := parse(kind, fmt.Sprintf("field %d name", idx), field.Name)
+		if errName != nil {
+			return compiled, errName
+		}
+
+		value, errValue := parse(kind, fmt.Sprintf("field %d value", idx), field.Value)
+		if errValue != nil {
+			return compiled, errValue
+		}
+
+		compiled.fields = append(compiled.fields, compiledField{name: name, value: value, inline: field.Inline})
+	}
+
+	return compiled, nil
+}
+
+func execute(tmpl *template.Template, data any, limit int) (string, error) {
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", errors.Wrapf(err, "Failed to render %s", tmpl.Name())
+	}
+
+	return truncate(strings.TrimSpace(out.String()), limit), nil
+}
+
+// truncate shortens value to at most limit characters, marking the cut with an ellipsis.
+func truncate(value string, limit int) string {
+	if utf8.RuneCountInString(value) <= limit {
+		return value
+	}
+
+	runes := []rune(value)
+
+	return string(runes[:limit-1]) + "…"
+}
+
+func (c compiledTemplate) render(data any, timestamp int) (Embed, error) {
+	var (
+		embed = Embed{Color: c.color}
+		err   error
+	)
+
+	if embed.Title, err = execute(c.title, data, maxTitle); err != nil {
+		return embed, err
+	}
+
+	if embed.Description, err = execute(c.description, data, maxDescription); err != nil {
+		return embed, err
+	}
+
+	if embed.URL, err = execute(c.url, data, maxDescription); err != nil {
+		return embed, err
+	}
+
+	for _, field := range c.fields {
+		name, errName := execute(field.name, data, maxFieldName)
+		if errName != nil {
+			return embed, errName
+		}
+
+		value, errValue := execute(field.value, data, maxFieldValue)
+		if errValue != nil {
+			return embed, errValue
+		}
+
+		if name == "" || value == "" {
+			continue
+		}
+
+		embed.Fields = append(embed.Fields, EmbedField{Name: name, Value: value, Inline: field.inline})
+	}
+
+	if timestamp > 0 {
+		embed.Timestamp = time.Unix(int64(timestamp), 0).UTC().Format(time.RFC3339)
+	}
+
+	return embed, nil
+}
+
+// Notifier renders events into embeds and posts them to a webhook.
+type Notifier struct {
+	// Username and AvatarURL override the webhook defaults when set.
+	Username  string
+	AvatarURL string
+	webhook   *Webhook
+	templates map[EventKind]compiledTemplate
+}
+
+// NewNotifier compiles the templates, returning an error if any of them are invalid.
+func NewNotifier(webhook *Webhook, templates Templates) (*Notifier, error) {
+	notifier := &Notifier{webhook: webhook, templates: map[EventKind]compiledTemplate{}}
+
+	for kind, tmpl := range map[EventKind]Template{
+		EventBan:      templates.Ban,
+		EventResult:   templates.Result,
+		EventReminder: templates.Reminder,
+		EventTransfer: templates.Transfer,
+	} {
+		compiled, err := compile(kind, tmpl)
+		if err != nil {
+			return nil, err
+		}
+
+		notifier.templates[kind] = compiled
+	}
+
+	return notifier, nil
+}
+
+// Embed renders the embed of an event without sending it. Data must be the event type matching the kind.
+func (n *Notifier) Embed(kind EventKind, data any) (Embed, error) {
+	tmpl, found := n.templates[kind]
+	if !found {
+		return Embed{}, errors.Errorf("Unknown event kind: %s", kind)
+	}
+
+	var timestamp int
+
+	switch event := data.(type) {
+	case *etf2l.Ban:
+		timestamp = event.Start
+	case *etf2l.Match:
+		timestamp = event.Time
+		if kind == EventResult && event.Submitted > 0 {
+			timestamp = event.Submitted
+		}
+	case *etf2l.TeamTransfer:
+		timestamp = event.Time
+	default:
+		return Embed{}, errors.Errorf("Unsupported event data: %T", data)
+	}
+
+	return tmpl.render(data, timestamp)
+}
+
+func (n *Notifier) send(ctx context.Context, kind EventKind, data any) error {
+	embed, errEmbed := n.Embed(kind, data)
+	if errEmbed != nil {
+		return errEmbed
+	}
+
+	return n.webhook.Send(ctx, Message{Username: n.Username, AvatarURL: n.AvatarURL, Embeds: []Embed{embed}})
+}
+
+// Ban announces a new ban.
+func (n *Notifier) Ban(ctx context.Context, ban etf2l.Ban) error {
+	return n.send(ctx, EventBan, &ban)
+}
+
+// MatchResult announces a submitted match result.
+func (n *Notifier) MatchResult(ctx context.Context, match etf2l.Match) error {
+	return n.send(ctx, EventResult, &match)
+}
+
+// MatchReminder announces an upcoming match.
+func (n *Notifier) MatchReminder(ctx context.Context, match etf2l.Match) error {
+	return n.send(ctx, EventReminder, &match)
+}
+
+// Transfer announces a roster change.
+func (n *Notifier) Transfer(ctx context.Context, transfer etf2l.TeamTransfer) error {
+	return n.send(ctx, EventTransfer, &transfer)
+}
+
+// DueReminders returns the unplayed matches starting between now and now+within, ordered by start time.
+func DueReminders(matches []etf2l.Match, now time.Time, within time.Duration) []etf2l.Match {
+	var due []etf2l.Match
+
+	for _, match := range matches {
+		start := time.Unix(int64(match.Time), 0)
+		if match.Submitted == 0 && match.Time > 0 && !start.Before(now) && !start.After(now.Add(within)) {
+			due = append(due, match)
+		}
+	}
+
+	slices.SortStableFunc(due, func(a, b etf2l.Match) int {
+		return a.Time - b.Time
+	})
+
+	return due
+}
+
+// WatchedTransfers returns the transfers of the watched teams made after since, ordered from oldest to newest.
+func WatchedTransfers(transfers []etf2l.TeamTransfer, teamIDs []int, since time.Time) []etf2l.TeamTransfer {
+	var watched []etf2l.TeamTransfer
+
+	for _, transfer := range transfers {
+		if slices.Contains(teamIDs, transfer.Team.ID) && time.Unix(int64(transfer.Time), 0).After(since) {
+			watched = append(watched, transfer)
+		}
+	}
+
+	slices.SortStableFunc(watched, func(a, b etf2l.TeamTransfer) int {
+		return a.Time - b.Time
+	})
+
+	return watched
+}
@@ -0,0 +1,212 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/etf2l"
+	"github.com/leighmacdonald/etf2l/notify"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver is a fake Discord webhook. Responses are taken from the queue in order, once it is empty every
+// message is accepted.
+type receiver struct {
+	mu        sync.Mutex
+	responses []func(writer http.ResponseWriter)
+	messages  []notify.Message
+	received  []time.Time
+}
+
+func (r *receiver) ServeHTTP(writer http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.received = append(r.received, time.Now())
+
+	if len(r.responses) > 0 {
+		respond := r.responses[0]
+		r.responses = r.responses[1:]
+		respond(writer)
+
+		return
+	}
+
+	var message notify.Message
+	if err := json.NewDecoder(req.Body).Decode(&message); err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	r.messages = append(r.messages, message)
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func newNotifier(t *testing.T, templates notify.Templates) (*notify.Notifier, *receiver) {
+	t.Helper()
+
+	fake := &receiver{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	notifier, err := notify.NewNotifier(notify.NewWebhook(server.URL, server.Client()), templates)
+	require.NoError(t, err)
+
+	return notifier, fake
+}
+
+func TestNotifier(t *testing.T) {
+	notifier, fake := newNotifier(t, notify.DefaultTemplates())
+	notifier.Username = "ETF2L"
+
+	ctx := context.Background()
+
+	ban := etf2l.Ban{Name: "cheater", Reason: "VAC", Steamid64: steamid.New("76561198203516436"), Start: 1700000000}
+	require.NoError(t, notifier.Ban(ctx, ban))
+
+	match := etf2l.Match{
+		ID:          7,
+		Clan1:       etf2l.MatchClan{Name: "Froyotech"},
+		Clan2:       etf2l.MatchClan{Name: "Se7en"},
+		Competition: etf2l.MatchCompetition{Name: "Season 50"},
+		Division:    etf2l.Division{Name: "Premiership"},
+		Maps:        []string{"cp_process_final", "koth_product_final"},
+		R1:          3,
+		R2:          1,
+		Time:        1700000000,
+		Submitted:   1700007200,
+	}
+	match.Urls.Self = "https://etf2l.org/matches/7/"
+	require.NoError(t, notifier.MatchResult(ctx, match))
+
+	transfer := etf2l.TeamTransfer{Who: etf2l.TransferPlayerInfo{Name: "b4nny", URL: "https://etf2l.org/forum/user/1/"}, Type: "joined", Time: 1700000000}
+	transfer.Team.Name = "Froyotech"
+	require.NoError(t, notifier.Transfer(ctx, transfer))
+
+	require.Len(t, fake.messages, 3)
+	require.Equal(t, "ETF2L", fake.messages[0].Username)
+
+	banEmbed := fake.messages[0].Embeds[0]
+	require.Equal(t, "New ban: cheater", banEmbed.Title)
+	require.Equal(t, "VAC", banEmbed.Description)
+	require.Equal(t, []notify.EmbedField{
+		{Name: "Steam ID", Value: "76561198203516436", Inline: true},
+		{Name: "Start", Value: "<t:1700000000:f>", Inline: true},
+	}, banEmbed.Fields)
+	require.Equal(t, "2023-11-14T22:13:20Z", banEmbed.Timestamp)
+
+	result := fake.messages[1].Embeds[0]
+	require.Equal(t, "Froyotech 3 - 1 Se7en", result.Title)
+	require.Equal(t, "Season 50 · Premiership", result.Description)
+	require.Equal(t, "https://etf2l.org/matches/7/", result.URL)
+	require.Equal(t, "2023-11-15T00:13:20Z", result.Timestamp)
+	require.Equal(t, "cp_process_final, koth_product_final", result.Fields[0].Value)
+
+	require.Equal(t, "b4nny joined Froyotech", fake.messages[2].Embeds[0].Title)
+	require.Equal(t, "[b4nny](https://etf2l.org/forum/user/1/)", fake.messages[2].Embeds[0].Fields[0].Value)
+
+	reminder, errReminder := notifier.Embed(notify.EventReminder, &match)
+	require.NoError(t, errReminder)
+	require.Equal(t, "Starts <t:1700000000:R>, <t:1700000000:f>", reminder.Description)
+
+	_, errKind := notifier.Embed(notify.EventBan, "not a ban")
+	require.Error(t, errKind)
+}
+
+func TestTemplates(t *testing.T) {
+	templates := notify.DefaultTemplates()
+	templates.Ban.Title = "{{.Name | upper}}"
+
+	_, errFunc := notify.NewNotifier(nil, templates)
+	require.Error(t, errFunc)
+
+	templates.Ban = notify.Template{Title: "{{.Name}} banned until {{(unix .End).Format \"2006-01-02\"}}", Description: "{{.Reason}}"}
+	notifier, fake := newNotifier(t, templates)
+
+	long := strings.Repeat("x", 5000)
+	require.NoError(t, notifier.Ban(context.Background(), etf2l.Ban{Name: "cheater", End: 1700000000, Reason: long}))
+
+	embed := fake.messages[0].Embeds[0]
+	require.Equal(t, "cheater banned until 2023-11-14", embed.Title)
+	require.Equal(t, 4096, len([]rune(embed.Description)))
+	require.True(t, strings.HasSuffix(embed.Description, "…"))
+	require.Empty(t, embed.Fields)
+}
+
+func TestWebhookRateLimits(t *testing.T) {
+	notifier, fake := newNotifier(t, notify.DefaultTemplates())
+	ctx := context.Background()
+	ban := etf2l.Ban{Name: "cheater", Steamid64: steamid.New("76561198203516436")}
+
+	fake.responses = []func(http.ResponseWriter){
+		func(writer http.ResponseWriter) {
+			writer.WriteHeader(http.StatusTooManyRequests)
+			_, _ = writer.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 0.1, "global": false}`))
+		},
+		func(writer http.ResponseWriter) {
+			writer.Header().Set("X-RateLimit-Remaining", "0")
+			writer.Header().Set("X-RateLimit-Reset-After", "0.1")
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	}
+
+	require.NoError(t, notifier.Ban(ctx, ban))
+	require.NoError(t, notifier.Ban(ctx, ban))
+
+	require.Len(t, fake.received, 3)
+	require.GreaterOrEqual(t, fake.received[1].Sub(fake.received[0]), 100*time.Millisecond)
+	require.GreaterOrEqual(t, fake.received[2].Sub(fake.received[1]), 100*time.Millisecond)
+
+	limited := func(writer http.ResponseWriter) {
+		writer.Header().Set("Retry-After", "0")
+		writer.WriteHeader(http.StatusTooManyRequests)
+	}
+	fake.responses = []func(http.ResponseWriter){limited, limited, limited, limited}
+	require.ErrorIs(t, notifier.Ban(ctx, ban), notify.ErrRateLimited)
+
+	fake.responses = []func(http.ResponseWriter){func(writer http.ResponseWriter) {
+		writer.WriteHeader(http.StatusBadRequest)
+		_, _ = writer.Write([]byte(`{"message": "Invalid Form Body"}`))
+	}}
+	errInvalid := notifier.Ban(ctx, ban)
+	require.ErrorContains(t, errInvalid, "Invalid Form Body")
+	require.NotErrorIs(t, errInvalid, notify.ErrRateLimited)
+}
+
+func TestEventSelection(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	matches := []etf2l.Match{
+		{ID: 1, Time: 1700003600},
+		{ID: 2, Time: 1700000600},
+		{ID: 3, Time: 1700000600, Submitted: 1700000700},
+		{ID: 4, Time: 1699990000},
+		{ID: 5, Time: 1800000000},
+	}
+
+	due := notify.DueReminders(matches, now, 2*time.Hour)
+	require.Len(t, due, 2)
+	require.Equal(t, 2, due[0].ID)
+	require.Equal(t, 1, due[1].ID)
+
+	transfer := func(teamID int, at int) etf2l.TeamTransfer {
+		var transfer etf2l.TeamTransfer
+		transfer.Team.ID = teamID
+		transfer.Time = at
+
+		return transfer
+	}
+
+	watched := notify.WatchedTransfers([]etf2l.TeamTransfer{
+		transfer(1, 1700000300), transfer(2, 1700000100), transfer(1, 1700000200), transfer(1, 1600000000),
+	}, []int{1}, now)
+	require.Len(t, watched, 2)
+	require.Equal(t, 1700000200, watched[0].Time)
+}
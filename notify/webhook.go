@@ -0,0 +1,174 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/leighmacdonald/etf2l"
+	"github.com/pkg/errors"
+)
+
+// DefaultMaxRetries is the number of times a rate limited message is resent before giving up.
+const DefaultMaxRetries = 3
+
+var ErrRateLimited = errors.New("Rate limited")
+
+// EmbedField is a name and value pair shown in an embed.
+type EmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type EmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// Embed is a Discord message embed.
+type Embed struct {
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	URL         string       `json:"url,omitempty"`
+	Color       int          `json:"color,omitempty"`
+	Timestamp   string       `json:"timestamp,omitempty"`
+	Fields      []EmbedField `json:"fields,omitempty"`
+	Footer      *EmbedFooter `json:"footer,omitempty"`
+}
+
+// Message is the payload of a webhook execution.
+type Message struct {
+	Content   string  `json:"content,omitempty"`
+	Username  string  `json:"username,omitempty"`
+	AvatarURL string  `json:"avatar_url,omitempty"`
+	Embeds    []Embed `json:"embeds,omitempty"`
+}
+
+// rateLimitResponse is the body of a 429 response.
+type rateLimitResponse struct {
+	Message    string  `json:"message"`
+	RetryAfter float64 `json:"retry_after"`
+	Global     bool    `json:"global"`
+}
+
+// Webhook sends messages to a Discord webhook. Messages are sent one at a time, waiting out the bucket reported
+// by the rate limit headers before sending the next one, and retrying messages which were rate limited.
+type Webhook struct {
+	URL string
+	// MaxRetries is the number of times a rate limited message is resent.
+	MaxRetries int
+	httpClient etf2l.HTTPExecutor
+	mu         sync.Mutex
+	resetAt    time.Time
+}
+
+func NewWebhook(webhookURL string, httpClient etf2l.HTTPExecutor) *Webhook {
+	return &Webhook{URL: webhookURL, MaxRetries: DefaultMaxRetries, httpClient: httpClient}
+}
+
+// Send executes the webhook, blocking while the webhook is rate limited.
+func (w *Webhook) Send(ctx context.Context, message Message) error {
+	body, errMarshal := json.Marshal(message)
+	if errMarshal != nil {
+		return errors.Wrap(errMarshal, "Failed to encode message")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		if err := sleepUntil(ctx, w.resetAt); err != nil {
+			return err
+		}
+
+		retryAfter, errSend := w.send(ctx, body)
+		if errSend == nil {
+			return nil
+		}
+
+		if !errors.Is(errSend, ErrRateLimited) || attempt >= w.MaxRetries {
+			return errSend
+		}
+
+		w.resetAt = time.Now().Add(retryAfter)
+	}
+}
+
+// send performs a single request. Rate limited requests return ErrRateLimited along with the time to wait.
+func (w *Webhook) send(ctx context.Context, body []byte) (time.Duration, error) {
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if errReq != nil {
+		return 0, errors.Wrap(errReq, "Failed to create request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, errResp := w.httpClient.Do(req)
+	if errResp != nil {
+		return 0, errors.Wrap(errResp, "Failed to call webhook")
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	respBody, errRead := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if errRead != nil {
+		return 0, errors.Wrap(errRead, "Failed to read response body")
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		var limited rateLimitResponse
+		if err := json.Unmarshal(respBody, &limited); err != nil || limited.RetryAfter <= 0 {
+			limited.RetryAfter = headerSeconds(resp.Header, "Retry-After")
+		}
+
+		return seconds(limited.RetryAfter), ErrRateLimited
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return 0, errors.Errorf("Invalid status code: %s: %s", resp.Status, bytes.TrimSpace(respBody))
+	}
+
+	// Wait for the bucket to refill before the next message once it has been used up.
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		w.resetAt = time.Now().Add(seconds(headerSeconds(resp.Header, "X-RateLimit-Reset-After")))
+	}
+
+	return 0, nil
+}
+
+func headerSeconds(header http.Header, key string) float64 {
+	value, err := strconv.ParseFloat(header.Get(key), 64)
+	if err != nil || value < 0 {
+		return 0
+	}
+
+	return value
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
+
+func sleepUntil(ctx context.Context, until time.Time) error {
+	wait := time.Until(until)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
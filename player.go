@@ -69,19 +69,34 @@ type PlayerClasses []string
 
 var errDecode = errors.New("failed to decode json response")
 
+// UnmarshalJSON accepts a list of class names. The api sends false or null instead of an empty list for players
+// without classes, both decode to an empty list.
 func (f *PlayerClasses) UnmarshalJSON(data []byte) error {
 	var value any
 	if err := json.Unmarshal(data, &value); err != nil {
 		return errors.Join(err, errDecode)
 	}
 
-	switch value.(type) {
-	case nil, bool, []interface{}:
-		*f = []string{}
-	default:
-		*f = value.([]string)
+	classes := []string{}
+
+	switch typed := value.(type) {
+	case string:
+		if typed != "" {
+			classes = append(classes, typed)
+		}
+	case []any:
+		for _, class := range typed {
+			name, ok := class.(string)
+			if !ok {
+				return errors.Join(fmt.Errorf("invalid class %v", class), errDecode)
+			}
+
+			classes = append(classes, name)
+		}
 	}
 
+	*f = classes
+
 	return nil
 }
 
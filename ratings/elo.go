@@ -0,0 +1,68 @@
+package ratings
+
+import (
+	"math"
+	"time"
+)
+
+// Elo is a classic Elo rating system.
+type Elo struct {
+	// K is the maximum rating change of a single game before weighting.
+	K float64
+	// Initial is the rating of a team's first game.
+	Initial float64
+	Options Options
+	history history
+}
+
+func NewElo(opts Options) *Elo {
+	return &Elo{K: 32, Initial: 1500, Options: opts, history: history{}}
+}
+
+// Seed sets the starting rating of a team, eg: to carry over ratings from a previous season.
+func (e *Elo) Seed(teamID int, rating float64, at time.Time) {
+	e.history.record(Rating{TeamID: teamID, Rating: rating, Updated: at})
+}
+
+func (e *Elo) rating(teamID int) Rating {
+	if rating, found := e.history.latest(teamID); found {
+		return rating
+	}
+
+	return Rating{TeamID: teamID, Rating: e.Initial}
+}
+
+// Rate applies the results in time order. It can be called repeatedly with newer results.
+func (e *Elo) Rate(results []Result) {
+	for _, result := range sortResults(results) {
+		for _, played := range e.Options.games(result) {
+			rating1, rating2 := e.rating(played.team1), e.rating(played.team2)
+			expected := 1 / (1 + math.Pow(10, (rating2.Rating-rating1.Rating)/400))
+			change := e.K * played.weight * (played.score - expected)
+
+			rating1.Rating += change
+			rating2.Rating -= change
+
+			for _, rating := range []*Rating{&rating1, &rating2} {
+				rating.Results++
+				rating.Updated = result.Time
+				e.history.record(*rating)
+			}
+		}
+	}
+}
+
+// Rating returns the current rating of a team.
+func (e *Elo) Rating(teamID int) (Rating, bool) {
+	return e.history.latest(teamID)
+}
+
+// Ratings returns the current rating of every team, highest first.
+func (e *Elo) Ratings() []Rating {
+	return e.history.current()
+}
+
+// RatingsAt returns the ratings as they were at the given time, highest first.
+func (e *Elo) RatingsAt(at time.Time) []Rating {
+	return e.history.at(at)
+}
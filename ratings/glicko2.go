@@ -0,0 +1,245 @@
+package ratings
+
+import (
+	"math"
+	"time"
+)
+
+// glickoScale converts between the Glicko and Glicko-2 scales.
+const glickoScale = 173.7178
+
+// Glicko2 implements the Glicko-2 rating system. Results are grouped into rating periods of Period length,
+// aligned to the unix epoch, and teams that do not play during a period have their deviation increased.
+type Glicko2 struct {
+	Initial           float64
+	InitialDeviation  float64
+	InitialVolatility float64
+	// Tau constrains the change in volatility over time, typically between 0.3 and 1.2.
+	Tau     float64
+	Period  time.Duration
+	Options Options
+	history history
+	// lastPeriod is the most recent period which has been rated, valid once rated is set.
+	lastPeriod int64
+	rated      bool
+}
+
+func NewGlicko2(opts Options) *Glicko2 {
+	return &Glicko2{
+		Initial:           1500,
+		InitialDeviation:  350,
+		InitialVolatility: 0.06,
+		Tau:               0.5,
+		Period:            7 * 24 * time.Hour,
+		Options:           opts,
+		history:           history{},
+	}
+}
+
+// Seed sets the starting rating of a team, eg: to carry over ratings from a previous season.
+func (g *Glicko2) Seed(rating Rating) {
+	g.history.record(rating)
+}
+
+func (g *Glicko2) rating(teamID int) Rating {
+	if rating, found := g.history.latest(teamID); found {
+		return rating
+	}
+
+	return Rating{TeamID: teamID, Rating: g.Initial, Deviation: g.InitialDeviation, Volatility: g.InitialVolatility}
+}
+
+// Rate applies the results period by period in time order. Every period between the last rated period and the
+// next one containing a result is rated without results, increasing the deviation of every team once for each.
+// Since every period up to the last result is closed, repeated calls should only be made with results from later
+// periods.
+func (g *Glicko2) Rate(results []Result) {
+	var (
+		sorted = sortResults(results)
+		period []Result
+	)
+
+	periodOf := func(at time.Time) int64 {
+		return at.UnixNano() / int64(g.Period)
+	}
+
+	periodEnd := func(number int64) time.Time {
+		return time.Unix(0, (number+1)*int64(g.Period)-1)
+	}
+
+	for idx, result := range sorted {
+		period = append(period, result)
+
+		if idx < len(sorted)-1 && periodOf(sorted[idx+1].Time) == periodOf(result.Time) {
+			continue
+		}
+
+		current := periodOf(result.Time)
+
+		if g.rated {
+			for inactive := g.lastPeriod + 1; inactive < current; inactive++ {
+				g.ratePeriod(nil, periodEnd(inactive))
+			}
+		}
+
+		g.ratePeriod(period, periodEnd(current))
+		g.lastPeriod, g.rated = current, true
+		period = nil
+	}
+}
+
+type opponent struct {
+	mu     float64
+	phi    float64
+	score  float64
+	weight float64
+}
+
+func (g *Glicko2) ratePeriod(results []Result, end time.Time) {
+	opponents := map[int][]opponent{}
+	// Opponents are rated using their ratings from before the period.
+	before := map[int]Rating{}
+
+	for _, result := range results {
+		for _, played := range g.Options.games(result) {
+			for _, teamID := range []int{played.team1, played.team2} {
+				if _, found := before[teamID]; !found {
+					before[teamID] = g.rating(teamID)
+				}
+			}
+
+			rating1, rating2 := before[played.team1], before[played.team2]
+			opponents[played.team1] = append(opponents[played.team1], opponent{
+				mu: toMu(rating2.Rating), phi: rating2.Deviation / glickoScale, score: played.score, weight: played.weight,
+			})
+			opponents[played.team2] = append(opponents[played.team2], opponent{
+				mu: toMu(rating1.Rating), phi: rating1.Deviation / glickoScale, score: 1 - played.score, weight: played.weight,
+			})
+		}
+	}
+
+	for _, current := range g.history.current() {
+		if _, played := opponents[current.TeamID]; played {
+			continue
+		}
+
+		// Inactive teams only become less certain.
+		phi := current.Deviation / glickoScale
+		current.Deviation = math.Min(math.Sqrt(phi*phi+current.Volatility*current.Volatility)*glickoScale, g.InitialDeviation)
+		current.Updated = end
+		g.history.record(current)
+	}
+
+	for teamID, games := range opponents {
+		updated := g.update(before[teamID], games)
+		updated.Updated = end
+		g.history.record(updated)
+	}
+}
+
+func toMu(rating float64) float64 {
+	return (rating - 1500) / glickoScale
+}
+
+func gFactor(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func expectedScore(mu float64, opponentMu float64, opponentPhi float64) float64 {
+	return 1 / (1 + math.Exp(-gFactor(opponentPhi)*(mu-opponentMu)))
+}
+
+// update applies step 3 through 8 of the Glicko-2 algorithm, with each game scaled by its weight.
+func (g *Glicko2) update(rating Rating, games []opponent) Rating {
+	mu := toMu(rating.Rating)
+	phi := rating.Deviation / glickoScale
+	sigma := rating.Volatility
+
+	var invV, sum float64
+
+	for _, game := range games {
+		gPhi := gFactor(game.phi)
+		expected := expectedScore(mu, game.mu, game.phi)
+		invV += game.weight * gPhi * gPhi * expected * (1 - expected)
+		sum += game.weight * gPhi * (game.score - expected)
+	}
+
+	if invV == 0 {
+		return rating
+	}
+
+	v := 1 / invV
+	delta := v * sum
+	sigma = g.volatility(phi, sigma, v, delta)
+
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	mu += phi * phi * sum
+
+	rating.Rating = mu*glickoScale + 1500
+	rating.Deviation = phi * glickoScale
+	rating.Volatility = sigma
+	rating.Results += len(games)
+
+	return rating
+}
+
+// volatility solves for the new volatility using the Illinois algorithm.
+func (g *Glicko2) volatility(phi float64, sigma float64, v float64, delta float64) float64 {
+	const epsilon = 0.000001
+
+	alpha := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		denominator := phi*phi + v + ex
+
+		return ex*(delta*delta-phi*phi-v-ex)/(2*denominator*denominator) - (x-alpha)/(g.Tau*g.Tau)
+	}
+
+	lower := alpha
+
+	var upper float64
+
+	if delta*delta > phi*phi+v {
+		upper = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(alpha-k*g.Tau) < 0 {
+			k++
+		}
+
+		upper = alpha - k*g.Tau
+	}
+
+	fLower, fUpper := f(lower), f(upper)
+
+	for math.Abs(upper-lower) > epsilon {
+		next := lower + (lower-upper)*fLower/(fUpper-fLower)
+		fNext := f(next)
+
+		if fNext*fUpper <= 0 {
+			lower, fLower = upper, fUpper
+		} else {
+			fLower /= 2
+		}
+
+		upper, fUpper = next, fNext
+	}
+
+	return math.Exp(lower / 2)
+}
+
+// Rating returns the current rating of a team.
+func (g *Glicko2) Rating(teamID int) (Rating, bool) {
+	return g.history.latest(teamID)
+}
+
+// Ratings returns the current rating of every team, highest first.
+func (g *Glicko2) Ratings() []Rating {
+	return g.history.current()
+}
+
+// RatingsAt returns the ratings as they were at the given time, highest first.
+func (g *Glicko2) RatingsAt(at time.Time) []Rating {
+	return g.history.at(at)
+}
@@ -0,0 +1,254 @@
+// Package ratings computes Elo and Glicko-2 team ratings from ETF2L match history.
+package ratings
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/leighmacdonald/etf2l"
+)
+
+// MapScore is the score of a single map of a result.
+type MapScore struct {
+	Score1 int
+	Score2 int
+}
+
+// Result is a single match between two teams in the form consumed by the rating systems.
+type Result struct {
+	MatchID    int
+	Time       time.Time
+	Team1      int
+	Team2      int
+	Score1     int
+	Score2     int
+	DefaultWin bool
+	Division   etf2l.Division
+	// Maps are optional and only used for map level updates.
+	Maps []MapScore
+}
+
+// FromMatch converts a match from the global match list.
+func FromMatch(match etf2l.Match) Result {
+	return Result{
+		MatchID:    match.ID,
+		Time:       time.Unix(int64(match.Time), 0),
+		Team1:      match.Clan1.ID,
+		Team2:      match.Clan2.ID,
+		Score1:     match.R1,
+		Score2:     match.R2,
+		DefaultWin: match.Defaultwin,
+		Division:   match.Division,
+	}
+}
+
+// FromMatchDetails converts a detailed match, including its per map results. The division is taken from div as
+// the details endpoint does not use a fixed structure for it.
+func FromMatchDetails(match etf2l.MatchDetails, div etf2l.Division) Result {
+	result := Result{
+		MatchID:    match.ID,
+		Time:       time.Unix(int64(match.Time), 0),
+		Team1:      match.Clan1.ID,
+		Team2:      match.Clan2.ID,
+		Score1:     match.R1,
+		Score2:     match.R2,
+		DefaultWin: match.Defaultwin,
+		Division:   div,
+	}
+
+	mapResults := slices.Clone(match.MapResults)
+	slices.SortStableFunc(mapResults, func(a, b etf2l.MatchMapResult) int {
+		return a.MatchOrder - b.MatchOrder
+	})
+
+	for _, mapResult := range mapResults {
+		result.Maps = append(result.Maps, MapScore{Score1: mapResult.Clan1, Score2: mapResult.Clan2})
+	}
+
+	return result
+}
+
+// FromCompetitionMatch converts a match of a competition.
+func FromCompetitionMatch(match etf2l.CompetitionMatch, played time.Time) Result {
+	return Result{
+		MatchID:    match.ID,
+		Time:       played,
+		Team1:      match.Clan1.ID,
+		Team2:      match.Clan2.ID,
+		Score1:     match.Result.R1,
+		Score2:     match.Result.R2,
+		DefaultWin: match.Defaultwin,
+		Division: etf2l.Division{
+			ID:           match.Division.ID,
+			Name:         match.Division.Name,
+			Tier:         match.Division.Tier,
+			SkillContrib: match.SkillContrib,
+		},
+	}
+}
+
+type Granularity int
+
+const (
+	// MatchLevel updates ratings once per match based on the overall result.
+	MatchLevel Granularity = iota
+	// MapLevel updates ratings once per map. Results without map scores fall back to a single match level update.
+	MapLevel
+)
+
+// WeightFunc returns the relative importance of a result played in a division, 1 being normal.
+type WeightFunc func(division etf2l.Division) float64
+
+// TierWeight weights results by division tier, each tier below the top (tier 0) is multiplied by decay.
+func TierWeight(decay float64) WeightFunc {
+	return func(division etf2l.Division) float64 {
+		return math.Pow(decay, float64(max(division.Tier, 0)))
+	}
+}
+
+// SkillContribWeight weights results by the skill contribution of their division, a division contributing
+// scale skill counts double.
+func SkillContribWeight(scale float64) WeightFunc {
+	return func(division etf2l.Division) float64 {
+		return 1 + float64(division.SkillContrib)/scale
+	}
+}
+
+// Options are shared by all rating systems.
+type Options struct {
+	Granularity Granularity
+	// IncludeDefaultWins counts default wins as played results, they are skipped by default.
+	IncludeDefaultWins bool
+	// Weight scales the impact of each result, defaults to weighting every result equally.
+	Weight WeightFunc
+}
+
+// Rating is the rating of a team at a point in time. Deviation and Volatility are only used by Glicko-2.
+type Rating struct {
+	TeamID     int
+	Rating     float64
+	Deviation  float64
+	Volatility float64
+	Results    int
+	Updated    time.Time
+}
+
+// game is a single rating update between two teams, derived from a result according to the Granularity.
+type game struct {
+	team1  int
+	team2  int
+	score  float64 // From the perspective of team1, 1 for a win, 0.5 for a draw.
+	weight float64
+}
+
+func (o Options) games(result Result) []game {
+	if result.Team1 == 0 || result.Team2 == 0 || result.DefaultWin && !o.IncludeDefaultWins {
+		return nil
+	}
+
+	weight := 1.0
+	if o.Weight != nil {
+		weight = o.Weight(result.Division)
+	}
+
+	if o.Granularity == MapLevel && len(result.Maps) > 0 && !result.DefaultWin {
+		games := make([]game, 0, len(result.Maps))
+		for _, score := range result.Maps {
+			games = append(games, game{result.Team1, result.Team2, actualScore(score.Score1, score.Score2), weight})
+		}
+
+		return games
+	}
+
+	if result.Score1 == 0 && result.Score2 == 0 && !result.DefaultWin {
+		// Not played yet.
+		return nil
+	}
+
+	return []game{{result.Team1, result.Team2, actualScore(result.Score1, result.Score2), weight}}
+}
+
+func actualScore(score1 int, score2 int) float64 {
+	switch {
+	case score1 > score2:
+		return 1
+	case score1 < score2:
+		return 0
+	default:
+		return 0.5
+	}
+}
+
+func sortResults(results []Result) []Result {
+	sorted := slices.Clone(results)
+	slices.SortStableFunc(sorted, func(a, b Result) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	return sorted
+}
+
+// history records every rating a team has held so that ratings can be looked up at any date.
+type history map[int][]Rating
+
+func (h history) record(rating Rating) {
+	h[rating.TeamID] = append(h[rating.TeamID], rating)
+}
+
+func (h history) latest(teamID int) (Rating, bool) {
+	ratings := h[teamID]
+	if len(ratings) == 0 {
+		return Rating{}, false
+	}
+
+	return ratings[len(ratings)-1], true
+}
+
+// at returns the ratings of every team rated at or before the time, highest rated first.
+func (h history) at(at time.Time) []Rating {
+	var out []Rating
+
+	for _, ratings := range h {
+		idx, _ := slices.BinarySearchFunc(ratings, at, func(rating Rating, target time.Time) int {
+			if rating.Updated.After(target) {
+				return 1
+			}
+
+			return -1
+		})
+
+		if idx > 0 {
+			out = append(out, ratings[idx-1])
+		}
+	}
+
+	sortRatings(out)
+
+	return out
+}
+
+func (h history) current() []Rating {
+	out := make([]Rating, 0, len(h))
+	for teamID := range h {
+		rating, _ := h.latest(teamID)
+		out = append(out, rating)
+	}
+
+	sortRatings(out)
+
+	return out
+}
+
+func sortRatings(ratings []Rating) {
+	slices.SortFunc(ratings, func(a, b Rating) int {
+		switch {
+		case a.Rating > b.Rating:
+			return -1
+		case a.Rating < b.Rating:
+			return 1
+		default:
+			return a.TeamID - b.TeamID
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package ratings_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/etf2l"
+	"github.com/leighmacdonald/etf2l/ratings"
+	"github.com/stretchr/testify/require"
+)
+
+func result(team1 int, team2 int, score1 int, score2 int, at time.Time) ratings.Result {
+	return ratings.Result{Team1: team1, Team2: team2, Score1: score1, Score2: score2, Time: at}
+}
+
+func TestElo(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	defaultWin := result(1, 3, 3, 0, start.Add(time.Hour))
+	defaultWin.DefaultWin = true
+
+	elo := ratings.NewElo(ratings.Options{})
+	elo.Rate([]ratings.Result{defaultWin, result(1, 2, 2, 1, start)})
+
+	first, found := elo.Rating(1)
+	require.True(t, found)
+	require.InDelta(t, 1516, first.Rating, 0.001)
+	require.Equal(t, 1, first.Results)
+
+	_, foundDefault := elo.Rating(3)
+	require.False(t, foundDefault)
+
+	elo.Rate([]ratings.Result{result(2, 1, 1, 0, start.Add(24*time.Hour))})
+	require.Less(t, elo.Ratings()[0].Rating, 1516.0)
+	require.Len(t, elo.RatingsAt(start.Add(time.Minute)), 2)
+	require.InDelta(t, 1516, elo.RatingsAt(start.Add(time.Minute))[0].Rating, 0.001)
+	require.Empty(t, elo.RatingsAt(start.Add(-time.Minute)))
+}
+
+func TestEloMapLevelWeighted(t *testing.T) {
+	match := ratings.Result{
+		Team1: 1, Team2: 2, Score1: 2, Score2: 1, Time: time.Unix(1000, 0),
+		Division: etf2l.Division{Tier: 2},
+		Maps:     []ratings.MapScore{{Score1: 5, Score2: 0}, {Score1: 0, Score2: 5}, {Score1: 5, Score2: 4}},
+	}
+
+	elo := ratings.NewElo(ratings.Options{Granularity: ratings.MapLevel, Weight: ratings.TierWeight(0.5)})
+	elo.Rate([]ratings.Result{match})
+
+	rating, _ := elo.Rating(1)
+	require.Equal(t, 3, rating.Results)
+	require.Greater(t, rating.Rating, 1500.0)
+	require.Less(t, rating.Rating, 1508.0)
+}
+
+// TestGlicko2 reproduces the worked example from Glickman's description of the Glicko-2 system.
+func TestGlicko2(t *testing.T) {
+	glicko := ratings.NewGlicko2(ratings.Options{})
+	glicko.Seed(ratings.Rating{TeamID: 1, Rating: 1500, Deviation: 200, Volatility: 0.06})
+	glicko.Seed(ratings.Rating{TeamID: 2, Rating: 1400, Deviation: 30, Volatility: 0.06})
+	glicko.Seed(ratings.Rating{TeamID: 3, Rating: 1550, Deviation: 100, Volatility: 0.06})
+	glicko.Seed(ratings.Rating{TeamID: 4, Rating: 1700, Deviation: 300, Volatility: 0.06})
+
+	start := time.Unix(0, 0).Add(glicko.Period * 3000)
+	glicko.Rate([]ratings.Result{
+		result(1, 2, 1, 0, start),
+		result(3, 1, 1, 0, start.Add(time.Hour)),
+		result(1, 4, 0, 1, start.Add(2*time.Hour)),
+	})
+
+	rating, found := glicko.Rating(1)
+	require.True(t, found)
+	require.InDelta(t, 1464.06, rating.Rating, 0.01)
+	require.InDelta(t, 151.52, rating.Deviation, 0.01)
+	require.InDelta(t, 0.05999, rating.Volatility, 0.00001)
+
+	// An inactive period only increases the deviation.
+	glicko.Rate([]ratings.Result{result(2, 3, 1, 0, start.Add(glicko.Period))})
+
+	inactive, _ := glicko.Rating(1)
+	require.InDelta(t, rating.Rating, inactive.Rating, 0.0001)
+	require.Greater(t, inactive.Deviation, rating.Deviation)
+
+	for _, snapshot := range glicko.RatingsAt(start.Add(glicko.Period)) {
+		if snapshot.TeamID == 1 {
+			require.Equal(t, rating, snapshot)
+		}
+	}
+}
+
+func TestGlicko2InactivePeriods(t *testing.T) {
+	glicko := ratings.NewGlicko2(ratings.Options{})
+	start := time.Unix(0, 0).Add(glicko.Period * 3000)
+
+	glicko.Rate([]ratings.Result{result(1, 2, 1, 0, start), result(3, 4, 1, 0, start)})
+	before, _ := glicko.Rating(1)
+
+	// Team 1 sits out ten periods while teams 3 and 4 play on the tenth.
+	const gap = 10
+
+	glicko.Rate([]ratings.Result{result(3, 4, 1, 0, start.Add(gap*glicko.Period))})
+
+	after, _ := glicko.Rating(1)
+	phi := before.Deviation / 173.7178
+	require.InDelta(t, math.Sqrt(phi*phi+gap*before.Volatility*before.Volatility)*173.7178, after.Deviation, 0.0001)
+	require.InDelta(t, before.Rating, after.Rating, 0.0001)
+
+	var midway ratings.Rating
+
+	for _, snapshot := range glicko.RatingsAt(start.Add(gap / 2 * glicko.Period)) {
+		if snapshot.TeamID == 1 {
+			midway = snapshot
+		}
+	}
+
+	require.Greater(t, midway.Deviation, before.Deviation)
+	require.Less(t, midway.Deviation, after.Deviation)
+}
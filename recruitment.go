@@ -78,7 +78,10 @@ type RecruitmentOpts struct {
 func (client *Client) PlayerRecruitment(ctx context.Context, httpClient HTTPExecutor, opts RecruitmentOpts) ([]PlayerRecruitment, error) {
 	var matches []PlayerRecruitment
 
-	curPath := "/recruitment/players"
+	curPath, errPath := queryPath("/recruitment/players", opts)
+	if errPath != nil {
+		return nil, errPath
+	}
 
 	for {
 		var resp playerRecruitmentResp
@@ -97,7 +100,10 @@ func (client *Client) PlayerRecruitment(ctx context.Context, httpClient HTTPExec
 			return nil, err
 		}
 
-		curPath = nextURL
+		curPath, errPath = pagePath(nextURL, opts)
+		if errPath != nil {
+			return nil, errPath
+		}
 	}
 
 	return matches, nil
@@ -154,7 +160,10 @@ func (resp teamRecruitmentResp) NextURL(r Recursive) (string, error) {
 func (client *Client) TeamRecruitment(ctx context.Context, httpClient HTTPExecutor, opts RecruitmentOpts) ([]TeamRecruitment, error) {
 	var matches []TeamRecruitment
 
-	curPath := "/recruitment/teams"
+	curPath, errPath := queryPath("/recruitment/teams", opts)
+	if errPath != nil {
+		return nil, errPath
+	}
 
 	for {
 		var resp teamRecruitmentResp
@@ -173,7 +182,10 @@ func (client *Client) TeamRecruitment(ctx context.Context, httpClient HTTPExecut
 			return nil, err
 		}
 
-		curPath = nextURL
+		curPath, errPath = pagePath(nextURL, opts)
+		if errPath != nil {
+			return nil, errPath
+		}
 	}
 
 	return matches, nil
@@ -0,0 +1,340 @@
+package etf2l
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultRecruitmentInterval is how often a RecruitmentPoller polls the recruitment posts.
+const DefaultRecruitmentInterval = 5 * time.Minute
+
+type RecruitmentEventKind string
+
+const (
+	RecruitmentPostAdded     RecruitmentEventKind = "added"
+	RecruitmentPostCommented RecruitmentEventKind = "commented"
+	RecruitmentPostRemoved   RecruitmentEventKind = "removed"
+)
+
+// RecruitmentPostState is what is remembered about a post between polls.
+type RecruitmentPostState struct {
+	Name     string              `json:"name"`
+	Comments RecruitmentComments `json:"comments"`
+}
+
+// RecruitmentSnapshot is the set of posts seen by the previous poll, keyed by post id.
+type RecruitmentSnapshot struct {
+	// Taken is zero when no snapshot has been saved yet.
+	Taken   time.Time                    `json:"taken"`
+	Players map[int]RecruitmentPostState `json:"players"`
+	Teams   map[int]RecruitmentPostState `json:"teams"`
+}
+
+// RecruitmentStateStore persists the snapshot between polls. LoadRecruitment returns a zero snapshot when
+// nothing has been saved.
+type RecruitmentStateStore interface {
+	LoadRecruitment(ctx context.Context) (RecruitmentSnapshot, error)
+	SaveRecruitment(ctx context.Context, snapshot RecruitmentSnapshot) error
+}
+
+// MemoryRecruitmentStore keeps the snapshot in memory, state is lost when the process exits.
+type MemoryRecruitmentStore struct {
+	mu       sync.Mutex
+	snapshot RecruitmentSnapshot
+}
+
+func (s *MemoryRecruitmentStore) LoadRecruitment(_ context.Context) (RecruitmentSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.snapshot, nil
+}
+
+func (s *MemoryRecruitmentStore) SaveRecruitment(_ context.Context, snapshot RecruitmentSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshot = snapshot
+
+	return nil
+}
+
+// FileRecruitmentStore keeps the snapshot in a json file.
+type FileRecruitmentStore struct {
+	Path string
+}
+
+func NewFileRecruitmentStore(path string) *FileRecruitmentStore {
+	return &FileRecruitmentStore{Path: path}
+}
+
+func (s *FileRecruitmentStore) LoadRecruitment(_ context.Context) (RecruitmentSnapshot, error) {
+	var snapshot RecruitmentSnapshot
+
+	body, errRead := os.ReadFile(s.Path)
+	if errRead != nil {
+		if errors.Is(errRead, os.ErrNotExist) {
+			return snapshot, nil
+		}
+
+		return snapshot, errors.Wrap(errRead, "Failed to read recruitment state")
+	}
+
+	if err := json.Unmarshal(body, &snapshot); err != nil {
+		return snapshot, errors.Wrap(err, "Failed to decode recruitment state")
+	}
+
+	return snapshot, nil
+}
+
+// SaveRecruitment writes the snapshot to a temporary file which replaces the previous state, so an interrupted
+// save does not lose it.
+func (s *FileRecruitmentStore) SaveRecruitment(_ context.Context, snapshot RecruitmentSnapshot) error {
+	body, errMarshal := json.Marshal(snapshot)
+	if errMarshal != nil {
+		return errors.Wrap(errMarshal, "Failed to encode recruitment state")
+	}
+
+	temp, errTemp := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if errTemp != nil {
+		return errors.Wrap(errTemp, "Failed to create recruitment state")
+	}
+
+	if _, err := temp.Write(body); err != nil {
+		_ = temp.Close()
+		_ = os.Remove(temp.Name())
+
+		return errors.Wrap(err, "Failed to write recruitment state")
+	}
+
+	if err := temp.Close(); err != nil {
+		_ = os.Remove(temp.Name())
+
+		return errors.Wrap(err, "Failed to write recruitment state")
+	}
+
+	if err := os.Rename(temp.Name(), s.Path); err != nil {
+		_ = os.Remove(temp.Name())
+
+		return errors.Wrap(err, "Failed to replace recruitment state")
+	}
+
+	return nil
+}
+
+// RecruitmentEvent describes a change to a single post. Exactly one of Player and Team is set for added and
+// commented posts, removed posts only carry the remembered State.
+type RecruitmentEvent struct {
+	Kind     RecruitmentEventKind
+	PostKind RecruitmentKind
+	PostID   int
+	Player   *PlayerRecruitment
+	Team     *TeamRecruitment
+	// State is the post as it was seen by the previous poll, it is the zero value for added posts.
+	State RecruitmentPostState
+}
+
+// RecruitmentPoller snapshots the player and team recruitment posts and reports the changes between polls.
+type RecruitmentPoller struct {
+	// Interval between polls made by Run.
+	Interval time.Duration
+	// Opts filters the posts which are tracked, paging is always recursive.
+	Opts RecruitmentOpts
+	// EmitInitial reports every post as added when the store holds no snapshot, otherwise the first poll only
+	// records the posts.
+	EmitInitial bool
+	// OnError is called by Run when a poll or saving the snapshot fails, errors are logged with slog when it is
+	// nil.
+	OnError    func(err error)
+	client     *Client
+	httpClient HTTPExecutor
+	store      RecruitmentStateStore
+}
+
+func NewRecruitmentPoller(client *Client, httpClient HTTPExecutor, store RecruitmentStateStore) *RecruitmentPoller {
+	return &RecruitmentPoller{
+		Interval:   DefaultRecruitmentInterval,
+		client:     client,
+		httpClient: httpClient,
+		store:      store,
+	}
+}
+
+// Poll fetches the posts, saves the new snapshot and returns the changes since the previous snapshot.
+func (p *RecruitmentPoller) Poll(ctx context.Context) ([]RecruitmentEvent, error) {
+	events, snapshot, errPoll := p.poll(ctx)
+	if errPoll != nil {
+		return nil, errPoll
+	}
+
+	if err := p.store.SaveRecruitment(ctx, snapshot); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// Run polls immediately and then every Interval, passing each event to onEvent. The snapshot is only saved once
+// every event of a poll has been handled, so events are delivered again after a failure. Failed polls and saves
+// are passed to OnError and retried on the next tick. It returns when ctx is cancelled or onEvent fails.
+func (p *RecruitmentPoller) Run(ctx context.Context, onEvent func(RecruitmentEvent) error) error {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = DefaultRecruitmentInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.runOnce(ctx, onEvent); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce performs a single poll of Run, only returning the errors which stop it.
+func (p *RecruitmentPoller) runOnce(ctx context.Context, onEvent func(RecruitmentEvent) error) error {
+	events, snapshot, errPoll := p.poll(ctx)
+	if errPoll != nil {
+		p.reportError(ctx, errPoll)
+
+		return nil
+	}
+
+	for _, event := range events {
+		if err := onEvent(event); err != nil {
+			return err
+		}
+	}
+
+	if err := p.store.SaveRecruitment(ctx, snapshot); err != nil {
+		p.reportError(ctx, err)
+	}
+
+	return nil
+}
+
+func (p *RecruitmentPoller) reportError(ctx context.Context, err error) {
+	// Failures caused by the cancellation are not reported, Run returns once the poll ends.
+	if ctx.Err() != nil {
+		return
+	}
+
+	if p.OnError != nil {
+		p.OnError(err)
+
+		return
+	}
+
+	slog.ErrorContext(ctx, "Failed to poll recruitment posts", slog.String("error", err.Error()))
+}
+
+func (p *RecruitmentPoller) poll(ctx context.Context) ([]RecruitmentEvent, RecruitmentSnapshot, error) {
+	previous, errLoad := p.store.LoadRecruitment(ctx)
+	if errLoad != nil {
+		return nil, RecruitmentSnapshot{}, errLoad
+	}
+
+	opts := p.Opts
+	opts.Recursive = true
+
+	players, errPlayers := p.client.PlayerRecruitment(ctx, p.httpClient, opts)
+	if errPlayers != nil {
+		return nil, RecruitmentSnapshot{}, errPlayers
+	}
+
+	teams, errTeams := p.client.TeamRecruitment(ctx, p.httpClient, opts)
+	if errTeams != nil {
+		return nil, RecruitmentSnapshot{}, errTeams
+	}
+
+	snapshot := RecruitmentSnapshot{
+		Taken:   time.Now(),
+		Players: map[int]RecruitmentPostState{},
+		Teams:   map[int]RecruitmentPostState{},
+	}
+
+	var events []RecruitmentEvent
+
+	for idx := range players {
+		post := &players[idx]
+		snapshot.Players[post.ID] = RecruitmentPostState{Name: post.Name, Comments: post.Comments}
+		events = appendPostEvent(events, RecruitmentPlayers, post.ID, previous.Players, snapshot.Players[post.ID], func(event *RecruitmentEvent) {
+			event.Player = post
+		})
+	}
+
+	for idx := range teams {
+		post := &teams[idx]
+		snapshot.Teams[post.ID] = RecruitmentPostState{Name: post.Name, Comments: post.Comments}
+		events = appendPostEvent(events, RecruitmentTeams, post.ID, previous.Teams, snapshot.Teams[post.ID], func(event *RecruitmentEvent) {
+			event.Team = post
+		})
+	}
+
+	events = appendRemoved(events, RecruitmentPlayers, previous.Players, snapshot.Players)
+	events = appendRemoved(events, RecruitmentTeams, previous.Teams, snapshot.Teams)
+
+	if previous.Taken.IsZero() && !p.EmitInitial {
+		events = nil
+	}
+
+	slices.SortStableFunc(events, func(a, b RecruitmentEvent) int {
+		if order := cmp.Compare(a.PostKind, b.PostKind); order != 0 {
+			return order
+		}
+
+		return cmp.Compare(a.PostID, b.PostID)
+	})
+
+	return events, snapshot, nil
+}
+
+func appendPostEvent(events []RecruitmentEvent, kind RecruitmentKind, postID int, previous map[int]RecruitmentPostState,
+	current RecruitmentPostState, setPost func(event *RecruitmentEvent),
+) []RecruitmentEvent {
+	event := RecruitmentEvent{PostKind: kind, PostID: postID}
+
+	state, found := previous[postID]
+
+	switch {
+	case !found:
+		event.Kind = RecruitmentPostAdded
+	case state.Comments != current.Comments:
+		event.Kind = RecruitmentPostCommented
+		event.State = state
+	default:
+		return events
+	}
+
+	setPost(&event)
+
+	return append(events, event)
+}
+
+func appendRemoved(events []RecruitmentEvent, kind RecruitmentKind, previous map[int]RecruitmentPostState, current map[int]RecruitmentPostState) []RecruitmentEvent {
+	for _, postID := range slices.Sorted(maps.Keys(previous)) {
+		if _, found := current[postID]; !found {
+			events = append(events, RecruitmentEvent{Kind: RecruitmentPostRemoved, PostKind: kind, PostID: postID, State: previous[postID]})
+		}
+	}
+
+	return events
+}
@@ -0,0 +1,170 @@
+package etf2l
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+)
+
+type TransferAction int
+
+const (
+	TransferUnknown TransferAction = iota
+	TransferJoin
+	TransferLeave
+	TransferRoleChange
+)
+
+const (
+	RolePlayer = "Player"
+	// RoleUnknown is the role of a member after a role change, the api does not say which role was given.
+	RoleUnknown = "Unknown"
+)
+
+// ParseTransferType maps the transfer type used by the api (joined, left and role_change) onto a TransferAction
+// along with the role the player holds afterwards, which is empty for players leaving the team.
+func ParseTransferType(transferType string) (TransferAction, string) {
+	value := strings.ToLower(transferType)
+
+	switch {
+	case strings.Contains(value, "role"):
+		return TransferRoleChange, RoleUnknown
+	case strings.Contains(value, "join"), strings.Contains(value, "add"), strings.Contains(value, "creat"):
+		return TransferJoin, RolePlayer
+	case strings.Contains(value, "left"), strings.Contains(value, "leave"), strings.Contains(value, "kick"),
+		strings.Contains(value, "remov"), strings.Contains(value, "delet"):
+		return TransferLeave, ""
+	default:
+		return TransferUnknown, ""
+	}
+}
+
+// RosterMember is a player who was on a team at a given point in time.
+type RosterMember struct {
+	Player TransferPlayerInfo
+	Role   string
+	// Joined is when the player most recently joined the team.
+	Joined time.Time
+}
+
+// RosterHistory replays the transfer history of a team to answer who was on the roster at any moment.
+type RosterHistory struct {
+	TeamID    int
+	transfers []TeamTransfer
+}
+
+// NewRosterHistory creates a history from the transfers of a team, which may be in any order. Transfers sharing a
+// timestamp are replayed joins first and leaves last, so a player who joined and left within the same second is
+// not on the roster afterwards.
+func NewRosterHistory(teamID int, transfers []TeamTransfer) RosterHistory {
+	sorted := slices.Clone(transfers)
+	slices.SortStableFunc(sorted, func(a, b TeamTransfer) int {
+		return cmp.Or(
+			cmp.Compare(a.Time, b.Time),
+			cmp.Compare(transferOrder(a), transferOrder(b)),
+			cmp.Compare(a.Who.ID, b.Who.ID),
+		)
+	})
+
+	return RosterHistory{TeamID: teamID, transfers: sorted}
+}
+
+// transferOrder is the position of a transfer among transfers made at the same time.
+func transferOrder(transfer TeamTransfer) int {
+	switch action, _ := ParseTransferType(transfer.Type); action {
+	case TransferJoin:
+		return 0
+	case TransferRoleChange:
+		return 1
+	case TransferLeave:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// At returns the roster as it was at the given time, including any transfers made at exactly that time. Members
+// are ordered by when they joined.
+func (h RosterHistory) At(at time.Time) []RosterMember {
+	var (
+		roster []RosterMember
+		cutoff = at.Unix()
+	)
+
+	for _, transfer := range h.transfers {
+		if int64(transfer.Time) > cutoff {
+			break
+		}
+
+		idx := slices.IndexFunc(roster, func(member RosterMember) bool { return member.Player.ID == transfer.Who.ID })
+		action, role := ParseTransferType(transfer.Type)
+
+		switch action {
+		case TransferJoin:
+			if idx >= 0 {
+				continue
+			}
+
+			roster = append(roster, RosterMember{
+				Player: transfer.Who,
+				Role:   role,
+				Joined: time.Unix(int64(transfer.Time), 0),
+			})
+		case TransferLeave:
+			if idx >= 0 {
+				roster = slices.Delete(roster, idx, idx+1)
+			}
+		case TransferRoleChange:
+			if idx >= 0 {
+				roster[idx].Role = role
+			}
+		case TransferUnknown:
+		}
+	}
+
+	return roster
+}
+
+// Contains reports whether the player was on the roster at the given time.
+func (h RosterHistory) Contains(playerID int, at time.Time) bool {
+	return slices.ContainsFunc(h.At(at), func(member RosterMember) bool {
+		return member.Player.ID == playerID
+	})
+}
+
+// JoinedAfter returns the first time the player joined the team after the given time, if ever.
+func (h RosterHistory) JoinedAfter(playerID int, at time.Time) (time.Time, bool) {
+	for _, transfer := range h.transfers {
+		if int64(transfer.Time) <= at.Unix() || transfer.Who.ID != playerID {
+			continue
+		}
+
+		if action, _ := ParseTransferType(transfer.Type); action == TransferJoin {
+			return time.Unix(int64(transfer.Time), 0), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// TeamRosterHistory fetches the complete transfer history for a team.
+func (client *Client) TeamRosterHistory(ctx context.Context, httpClient HTTPExecutor, teamID int) (RosterHistory, error) {
+	transfers, err := client.TeamTransfers(ctx, httpClient, teamID, BaseOpts{Recursive: true})
+	if err != nil {
+		return RosterHistory{}, err
+	}
+
+	return NewRosterHistory(teamID, transfers), nil
+}
+
+// RosterAt reconstructs the roster of a team at the given time from its transfer history.
+func (client *Client) RosterAt(ctx context.Context, httpClient HTTPExecutor, teamID int, at time.Time) ([]RosterMember, error) {
+	history, err := client.TeamRosterHistory(ctx, httpClient, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	return history.At(at), nil
+}
@@ -0,0 +1,370 @@
+package etf2l
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// StrictMode controls how the client reacts to responses which do not match the structs they are decoded into.
+type StrictMode int
+
+const (
+	// StrictOff decodes responses leniently, silently ignoring unknown fields. This is the default.
+	StrictOff StrictMode = iota
+	// StrictWarn records any schema drift, available via Client.SchemaIssues, and logs a warning the first time
+	// each issue is seen. The response is still decoded as normal.
+	StrictWarn
+	// StrictFail causes any call whose response drifts from the schema to fail with a *SchemaError.
+	StrictFail
+)
+
+var ErrSchemaDrift = errors.New("response does not match schema")
+
+// WithStrictDecoding enables detection of api schema changes, such as new, renamed or retyped fields.
+func WithStrictDecoding(mode StrictMode) Option {
+	return func(client *Client) {
+		client.strict = mode
+	}
+}
+
+type SchemaIssueKind string
+
+const (
+	UnknownField SchemaIssueKind = "unknown_field"
+	TypeMismatch SchemaIssueKind = "type_mismatch"
+)
+
+// SchemaIssue describes a single difference between a response and the struct it was decoded into.
+type SchemaIssue struct {
+	Endpoint string
+	// Field is the dotted json path of the offending field, array elements are represented by [] and map values by *.
+	Field  string
+	Kind   SchemaIssueKind
+	Detail string
+}
+
+func (issue SchemaIssue) String() string {
+	return fmt.Sprintf("%s %s: %s (%s)", issue.Endpoint, issue.Field, issue.Kind, issue.Detail)
+}
+
+// SchemaError is returned in StrictFail mode when a response drifts from the expected schema.
+type SchemaError struct {
+	Endpoint string
+	Issues   []SchemaIssue
+}
+
+func (e *SchemaError) Error() string {
+	issues := make([]string, len(e.Issues))
+	for idx, issue := range e.Issues {
+		issues[idx] = issue.String()
+	}
+
+	return fmt.Sprintf("%s: %s", ErrSchemaDrift.Error(), strings.Join(issues, ", "))
+}
+
+func (e *SchemaError) Unwrap() error {
+	return ErrSchemaDrift
+}
+
+// schemaIssues tracks the unique issues seen for each endpoint.
+type schemaIssues struct {
+	sync.Mutex
+	seen map[string]map[SchemaIssue]struct{}
+}
+
+// add records the issues, returning only those which had not been seen before.
+func (s *schemaIssues) add(issues []SchemaIssue) []SchemaIssue {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.seen == nil {
+		s.seen = map[string]map[SchemaIssue]struct{}{}
+	}
+
+	var added []SchemaIssue
+
+	for _, issue := range issues {
+		if _, found := s.seen[issue.Endpoint]; !found {
+			s.seen[issue.Endpoint] = map[SchemaIssue]struct{}{}
+		}
+
+		if _, found := s.seen[issue.Endpoint][issue]; found {
+			continue
+		}
+
+		s.seen[issue.Endpoint][issue] = struct{}{}
+		added = append(added, issue)
+	}
+
+	return added
+}
+
+// SchemaIssues returns every unique schema issue recorded so far, keyed by endpoint. Issues are only recorded when
+// strict decoding is enabled.
+func (client *Client) SchemaIssues() map[string][]SchemaIssue {
+	client.schema.Lock()
+	defer client.schema.Unlock()
+
+	out := map[string][]SchemaIssue{}
+
+	for endpoint, issues := range client.schema.seen {
+		for issue := range issues {
+			out[endpoint] = append(out[endpoint], issue)
+		}
+
+		sortIssues(out[endpoint])
+	}
+
+	return out
+}
+
+func sortIssues(issues []SchemaIssue) {
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Field == issues[j].Field {
+			return issues[i].Kind < issues[j].Kind
+		}
+
+		return issues[i].Field < issues[j].Field
+	})
+}
+
+// checkSchema compares the response body against the receiver according to the configured StrictMode.
+func (client *Client) checkSchema(path string, body []byte, receiver any) error {
+	if client.strict == StrictOff {
+		return nil
+	}
+
+	var raw any
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return errors.Wrap(err, "Failed to unmarshal json payload")
+	}
+
+	endpoint := endpointName(path)
+	issues := diffSchema(endpoint, raw, reflect.TypeOf(receiver), "")
+
+	for _, issue := range client.schema.add(issues) {
+		slog.Warn("ETF2L schema drift detected", slog.String("endpoint", issue.Endpoint),
+			slog.String("field", issue.Field), slog.String("kind", string(issue.Kind)), slog.String("detail", issue.Detail))
+	}
+
+	if client.strict == StrictFail && len(issues) > 0 {
+		return &SchemaError{Endpoint: endpoint, Issues: issues}
+	}
+
+	return nil
+}
+
+var (
+	jsonUnmarshaler = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshaler = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// diffSchema walks the generically decoded json value alongside the go type it would be decoded into, reporting
+// any fields the type does not know about and values whose json type cannot be stored in the go type.
+func diffSchema(endpoint string, value any, typ reflect.Type, path string) []SchemaIssue {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	// Types with custom decoding, and null values, are accepted as is.
+	if value == nil || typ.Kind() == reflect.Interface ||
+		reflect.PointerTo(typ).Implements(jsonUnmarshaler) || reflect.PointerTo(typ).Implements(textUnmarshaler) {
+		return nil
+	}
+
+	mismatch := func(expected string) []SchemaIssue {
+		return []SchemaIssue{{
+			Endpoint: endpoint,
+			Field:    strings.TrimPrefix(path, "."),
+			Kind:     TypeMismatch,
+			Detail:   fmt.Sprintf("expected %s, got %s", expected, jsonKind(value)),
+		}}
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		object, isObject := value.(map[string]any)
+		if !isObject {
+			return mismatch("object")
+		}
+
+		return diffStruct(endpoint, object, typ, path)
+	case reflect.Map:
+		object, isObject := value.(map[string]any)
+		if !isObject {
+			return mismatch("object")
+		}
+
+		var issues []SchemaIssue
+		for _, key := range sortedKeys(object) {
+			issues = append(issues, diffSchema(endpoint, object[key], typ.Elem(), path+".*")...)
+		}
+
+		return dedupeIssues(issues)
+	case reflect.Slice, reflect.Array:
+		array, isArray := value.([]any)
+		if !isArray {
+			return mismatch("array")
+		}
+
+		var issues []SchemaIssue
+		for _, elem := range array {
+			issues = append(issues, diffSchema(endpoint, elem, typ.Elem(), path+"[]")...)
+		}
+
+		return dedupeIssues(issues)
+	case reflect.String:
+		if _, isString := value.(string); !isString {
+			return mismatch("string")
+		}
+	case reflect.Bool:
+		if _, isBool := value.(bool); !isBool {
+			return mismatch("bool")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, isNumber := value.(float64)
+		if !isNumber || number != float64(int64(number)) {
+			return mismatch("integer")
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, isNumber := value.(float64); !isNumber {
+			return mismatch("number")
+		}
+	default:
+	}
+
+	return nil
+}
+
+func diffStruct(endpoint string, object map[string]any, typ reflect.Type, path string) []SchemaIssue {
+	fields := jsonFields(typ)
+
+	var issues []SchemaIssue
+
+	for _, key := range sortedKeys(object) {
+		field, found := fields[key]
+		if !found {
+			// encoding/json falls back to case-insensitive matching.
+			for name, candidate := range fields {
+				if strings.EqualFold(name, key) {
+					field, found = candidate, true
+
+					break
+				}
+			}
+		}
+
+		if !found {
+			issues = append(issues, SchemaIssue{
+				Endpoint: endpoint,
+				Field:    strings.TrimPrefix(path+"."+key, "."),
+				Kind:     UnknownField,
+				Detail:   "got " + jsonKind(object[key]),
+			})
+
+			continue
+		}
+
+		issues = append(issues, diffSchema(endpoint, object[key], field.Type, path+"."+key)...)
+	}
+
+	return issues
+}
+
+// jsonFields returns the fields of a struct keyed by their json name, including those promoted from embedded
+// structs.
+func jsonFields(typ reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+
+	for idx := range typ.NumField() {
+		field := typ.Field(idx)
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			for embeddedName, embedded := range jsonFields(fieldType) {
+				if _, exists := fields[embeddedName]; !exists {
+					fields[embeddedName] = embedded
+				}
+			}
+
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fields[name] = field
+	}
+
+	return fields
+}
+
+func jsonKind(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case float64:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func sortedKeys(object map[string]any) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// dedupeIssues collapses the identical issues reported for each element of an array or map.
+func dedupeIssues(issues []SchemaIssue) []SchemaIssue {
+	seen := map[SchemaIssue]struct{}{}
+	out := issues[:0]
+
+	for _, issue := range issues {
+		if _, found := seen[issue]; found {
+			continue
+		}
+
+		seen[issue] = struct{}{}
+		out = append(out, issue)
+	}
+
+	return out
+}
@@ -0,0 +1,504 @@
+package etf2l
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// StandingsTeam identifies a team within a standings calculation.
+type StandingsTeam struct {
+	ID      int
+	Name    string
+	Country string
+	Drop    bool
+}
+
+// StandingsMatch is the subset of a result needed to compute standings. R1 and R2 are the maps won by each team.
+// A match where either team has an id of 0 is treated as a bye for the other team.
+type StandingsMatch struct {
+	ID           int
+	DivisionID   int
+	DivisionName string
+	Clan1        StandingsTeam
+	Clan2        StandingsTeam
+	R1           int
+	R2           int
+	DefaultWin   bool
+	// MapResults are optional, they are required to award golden cap points.
+	MapResults []MatchMapResult
+}
+
+// played reports whether the match has a result, unplayed fixtures are reported with a 0-0 score.
+func (m StandingsMatch) played() bool {
+	return m.R1 != 0 || m.R2 != 0 || m.DefaultWin || len(m.MapResults) > 0
+}
+
+// StandingsMatchFromResult converts a competition result for use with ComputeStandings.
+func StandingsMatchFromResult(result CompetitionResult) StandingsMatch {
+	return StandingsMatch{
+		ID:           result.ID,
+		DivisionID:   result.Division.ID,
+		DivisionName: result.Division.Name,
+		Clan1:        StandingsTeam{ID: result.Clan1.ID, Name: result.Clan1.Name, Country: result.Clan1.Country, Drop: result.Clan1.Drop},
+		Clan2:        StandingsTeam{ID: result.Clan2.ID, Name: result.Clan2.Name, Country: result.Clan2.Country, Drop: result.Clan2.Drop},
+		R1:           result.R1,
+		R2:           result.R2,
+		DefaultWin:   result.Defaultwin,
+	}
+}
+
+// StandingsMatchFromCompetitionMatch converts a competition match for use with ComputeStandings.
+func StandingsMatchFromCompetitionMatch(match CompetitionMatch) StandingsMatch {
+	return StandingsMatch{
+		ID:           match.ID,
+		DivisionID:   match.Division.ID,
+		DivisionName: match.Division.Name,
+		Clan1:        StandingsTeam{ID: match.Clan1.ID, Name: match.Clan1.Name, Country: match.Clan1.Country, Drop: match.Clan1.Drop},
+		Clan2:        StandingsTeam{ID: match.Clan2.ID, Name: match.Clan2.Name, Country: match.Clan2.Country, Drop: match.Clan2.Drop},
+		R1:           match.Result.R1,
+		R2:           match.Result.R2,
+		DefaultWin:   match.Defaultwin,
+	}
+}
+
+// Scoring configures how many points are awarded. Map points are awarded for each map won, drawn or lost. When
+// map results are available, maps decided by a golden cap award the golden cap points instead.
+type Scoring struct {
+	MapWin        int
+	MapDraw       int
+	MapLoss       int
+	GoldenCapWin  int
+	GoldenCapLoss int
+	// Match points are awarded once per match in addition to the map points.
+	MatchWin  int
+	MatchDraw int
+	MatchLoss int
+	// ByePoints are awarded for each bye a team receives.
+	ByePoints int
+}
+
+// DefaultScoring awards 3 points per map win, with golden caps splitting the points 2 to 1.
+func DefaultScoring() Scoring {
+	return Scoring{MapWin: 3, GoldenCapWin: 2, GoldenCapLoss: 1, ByePoints: 3}
+}
+
+// StandingsAdjustment holds the manual point changes applied to a team by admins.
+type StandingsAdjustment struct {
+	PenaltyPoints int
+	SeededPoints  int
+}
+
+// StandingsRow is a single team row of a computed table.
+type StandingsRow struct {
+	Position      int
+	DivisionID    int
+	DivisionName  string
+	Team          StandingsTeam
+	MatchesPlayed int
+	MapsPlayed    int
+	MapsWon       int
+	MapsLost      int
+	GcWon         int
+	GcLost        int
+	PenaltyPoints int
+	SeededPoints  int
+	Byes          int
+	Score         int
+}
+
+// Tiebreaker separates teams with equal scores. It returns a value for each of the tied rows, in the same order,
+// rows with a higher value rank above those with a lower one and rows with equal values remain tied.
+type Tiebreaker func(tied []StandingsRow, matches []StandingsMatch) []int
+
+// TiebreakMapDifference ranks the team with the better maps won minus maps lost higher.
+func TiebreakMapDifference(tied []StandingsRow, _ []StandingsMatch) []int {
+	values := make([]int, len(tied))
+	for idx, row := range tied {
+		values[idx] = row.MapsWon - row.MapsLost
+	}
+
+	return values
+}
+
+// TiebreakMapsWon ranks the team with more maps won higher.
+func TiebreakMapsWon(tied []StandingsRow, _ []StandingsMatch) []int {
+	values := make([]int, len(tied))
+	for idx, row := range tied {
+		values[idx] = row.MapsWon
+	}
+
+	return values
+}
+
+// TiebreakHeadToHead builds a mini table of the matches played between the tied teams and ranks the team with
+// the better map difference within it higher.
+func TiebreakHeadToHead(tied []StandingsRow, matches []StandingsMatch) []int {
+	var (
+		values  = make([]int, len(tied))
+		indexes = map[int]int{}
+	)
+
+	for idx, row := range tied {
+		indexes[row.Team.ID] = idx
+	}
+
+	for _, match := range matches {
+		idx1, found1 := indexes[match.Clan1.ID]
+		idx2, found2 := indexes[match.Clan2.ID]
+
+		if !found1 || !found2 {
+			continue
+		}
+
+		values[idx1] += match.R1 - match.R2
+		values[idx2] += match.R2 - match.R1
+	}
+
+	return values
+}
+
+// TiebreakPenalties ranks the team with fewer penalty points higher.
+func TiebreakPenalties(tied []StandingsRow, _ []StandingsMatch) []int {
+	values := make([]int, len(tied))
+	for idx, row := range tied {
+		values[idx] = -row.PenaltyPoints
+	}
+
+	return values
+}
+
+// DefaultTiebreakers are applied in order when no tiebreakers are configured.
+func DefaultTiebreakers() []Tiebreaker {
+	return []Tiebreaker{TiebreakHeadToHead, TiebreakMapDifference, TiebreakMapsWon}
+}
+
+// StandingsConfig configures ComputeStandings.
+type StandingsConfig struct {
+	Scoring     Scoring
+	Tiebreakers []Tiebreaker
+	// Adjustments are keyed by team id.
+	Adjustments map[int]StandingsAdjustment
+}
+
+// AdjustmentsFromTables extracts the penalty and seeded points from the official tables so that they can be
+// reapplied to computed standings.
+func AdjustmentsFromTables(tables map[string]CompetitionTable) map[int]StandingsAdjustment {
+	adjustments := map[int]StandingsAdjustment{}
+
+	for _, table := range tables {
+		adjustments[table.TeamID] = StandingsAdjustment{
+			PenaltyPoints: table.PenaltyPoints,
+			SeededPoints:  table.SeededPoints,
+		}
+	}
+
+	return adjustments
+}
+
+// ComputeStandings builds the tables for every division present in matches. Rows are ordered by division and
+// then position, dropped teams are placed below all active teams of their division. Teams tied after all
+// tiebreakers share a position and are ordered by name.
+func ComputeStandings(matches []StandingsMatch, config StandingsConfig) []StandingsRow {
+	tiebreakers := config.Tiebreakers
+	if tiebreakers == nil {
+		tiebreakers = DefaultTiebreakers()
+	}
+
+	var (
+		rows    []StandingsRow
+		indexes = map[int]int{}
+		played  []StandingsMatch
+	)
+
+	row := func(match StandingsMatch, team StandingsTeam) *StandingsRow {
+		idx, found := indexes[team.ID]
+		if !found {
+			idx = len(rows)
+			indexes[team.ID] = idx
+			adjustment := config.Adjustments[team.ID]
+			rows = append(rows, StandingsRow{
+				DivisionID:    match.DivisionID,
+				DivisionName:  match.DivisionName,
+				Team:          team,
+				PenaltyPoints: adjustment.PenaltyPoints,
+				SeededPoints:  adjustment.SeededPoints,
+			})
+		}
+
+		if team.Drop {
+			rows[idx].Team.Drop = true
+		}
+
+		return &rows[idx]
+	}
+
+	for _, match := range matches {
+		switch {
+		case match.Clan1.ID == 0 && match.Clan2.ID == 0:
+			continue
+		case match.Clan2.ID == 0:
+			row(match, match.Clan1).Byes++
+		case match.Clan1.ID == 0:
+			row(match, match.Clan2).Byes++
+		case match.played():
+			played = append(played, match)
+			config.Scoring.apply(row(match, match.Clan1), match.R1, match.R2, match.MapResults, true)
+			config.Scoring.apply(row(match, match.Clan2), match.R2, match.R1, match.MapResults, false)
+		default:
+			// Register the teams of upcoming fixtures so they appear in the table.
+			row(match, match.Clan1)
+			row(match, match.Clan2)
+		}
+	}
+
+	for idx := range rows {
+		rows[idx].Score += rows[idx].Byes*config.Scoring.ByePoints - rows[idx].PenaltyPoints + rows[idx].SeededPoints
+	}
+
+	slices.SortStableFunc(rows, func(a, b StandingsRow) int {
+		if a.DivisionID != b.DivisionID {
+			return a.DivisionID - b.DivisionID
+		}
+
+		if a.Team.Drop != b.Team.Drop {
+			if a.Team.Drop {
+				return 1
+			}
+
+			return -1
+		}
+
+		if a.Score != b.Score {
+			return b.Score - a.Score
+		}
+
+		return strings.Compare(a.Team.Name, b.Team.Name)
+	})
+
+	ranked := make([]StandingsRow, 0, len(rows))
+	divisionStart := 0
+
+	for start := 0; start < len(rows); {
+		end := start + 1
+		for end < len(rows) && rows[end].DivisionID == rows[start].DivisionID &&
+			rows[end].Team.Drop == rows[start].Team.Drop && rows[end].Score == rows[start].Score {
+			end++
+		}
+
+		if start == 0 || rows[start-1].DivisionID != rows[start].DivisionID {
+			divisionStart = len(ranked)
+		}
+
+		for _, group := range breakTies(rows[start:end], tiebreakers, 0, played) {
+			position := len(ranked) - divisionStart + 1
+			for _, member := range group {
+				member.Position = position
+				ranked = append(ranked, member)
+			}
+		}
+
+		start = end
+	}
+
+	return ranked
+}
+
+// breakTies splits rows with equal scores into groups which are ordered by the tiebreakers, starting from the
+// tiebreaker at index next. Whenever a tiebreaker splits the rows, each smaller group starts over from the first
+// tiebreaker so that head-to-head results are recomputed between the teams which are still tied. Rows within a
+// group are ordered by name.
+func breakTies(tied []StandingsRow, tiebreakers []Tiebreaker, next int, matches []StandingsMatch) [][]StandingsRow {
+	if len(tied) < 2 || next >= len(tiebreakers) {
+		return [][]StandingsRow{tied}
+	}
+
+	values := tiebreakers[next](tied, matches)
+	order := make([]int, len(tied))
+
+	for idx := range order {
+		order[idx] = idx
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		return values[b] - values[a]
+	})
+
+	var groups [][]StandingsRow
+
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && values[order[end]] == values[order[start]] {
+			end++
+		}
+
+		group := make([]StandingsRow, 0, end-start)
+		for _, idx := range order[start:end] {
+			group = append(group, tied[idx])
+		}
+
+		groups = append(groups, group)
+		start = end
+	}
+
+	if len(groups) == 1 {
+		return breakTies(tied, tiebreakers, next+1, matches)
+	}
+
+	var ordered [][]StandingsRow
+	for _, group := range groups {
+		ordered = append(ordered, breakTies(group, tiebreakers, 0, matches)...)
+	}
+
+	return ordered
+}
+
+// apply adds the result of a single match to a row, from the perspective of that team.
+func (s Scoring) apply(row *StandingsRow, mapsWon int, mapsLost int, mapResults []MatchMapResult, isClan1 bool) {
+	row.MatchesPlayed++
+	row.MapsWon += mapsWon
+	row.MapsLost += mapsLost
+	row.MapsPlayed += mapsWon + mapsLost
+
+	switch outcome(mapsWon, mapsLost) {
+	case OutcomeWin:
+		row.Score += s.MatchWin
+	case OutcomeLoss:
+		row.Score += s.MatchLoss
+	case OutcomeDraw:
+		row.Score += s.MatchDraw
+	}
+
+	if len(mapResults) == 0 {
+		row.Score += mapsWon*s.MapWin + mapsLost*s.MapLoss
+
+		return
+	}
+
+	for _, result := range mapResults {
+		own, opponent := result.Clan1, result.Clan2
+		if !isClan1 {
+			own, opponent = opponent, own
+		}
+
+		mapOutcome := outcome(own, opponent)
+
+		switch {
+		case result.GoldenCap && mapOutcome == OutcomeWin:
+			row.GcWon++
+			row.Score += s.GoldenCapWin
+		case result.GoldenCap && mapOutcome == OutcomeLoss:
+			row.GcLost++
+			row.Score += s.GoldenCapLoss
+		case mapOutcome == OutcomeWin:
+			row.Score += s.MapWin
+		case mapOutcome == OutcomeLoss:
+			row.Score += s.MapLoss
+		default:
+			row.Score += s.MapDraw
+		}
+	}
+}
+
+// StandingsDiff is a single difference between a computed row and the official table.
+type StandingsDiff struct {
+	TeamID   int
+	Name     string
+	Field    string
+	Computed int
+	Official int
+}
+
+func (d StandingsDiff) String() string {
+	return fmt.Sprintf("%s (%d) %s: computed %d, official %d", d.Name, d.TeamID, d.Field, d.Computed, d.Official)
+}
+
+// DiffStandings compares computed standings against the official tables. Teams missing from either side are
+// reported with a Field of "missing".
+func DiffStandings(computed []StandingsRow, official map[string]CompetitionTable) []StandingsDiff {
+	var (
+		diffs     []StandingsDiff
+		officials = map[int]CompetitionTable{}
+	)
+
+	for _, table := range official {
+		officials[table.TeamID] = table
+	}
+
+	for _, row := range computed {
+		table, found := officials[row.Team.ID]
+		if !found {
+			diffs = append(diffs, StandingsDiff{TeamID: row.Team.ID, Name: row.Team.Name, Field: "missing", Computed: 1})
+
+			continue
+		}
+
+		delete(officials, row.Team.ID)
+
+		for _, field := range []struct {
+			name     string
+			computed int
+			official int
+		}{
+			{"maps_played", row.MapsPlayed, table.MapsPlayed},
+			{"maps_won", row.MapsWon, table.MapsWon},
+			{"maps_lost", row.MapsLost, table.MapsLost},
+			{"gc_won", row.GcWon, table.GcWon},
+			{"gc_lost", row.GcLost, table.GcLost},
+			{"byes", row.Byes, table.Byes},
+			{"score", row.Score, table.Score},
+		} {
+			if field.computed != field.official {
+				diffs = append(diffs, StandingsDiff{
+					TeamID:   row.Team.ID,
+					Name:     row.Team.Name,
+					Field:    field.name,
+					Computed: field.computed,
+					Official: field.official,
+				})
+			}
+		}
+	}
+
+	for _, table := range officials {
+		diffs = append(diffs, StandingsDiff{TeamID: table.TeamID, Name: table.Name, Field: "missing", Official: 1})
+	}
+
+	slices.SortStableFunc(diffs, func(a, b StandingsDiff) int {
+		if a.TeamID != b.TeamID {
+			return a.TeamID - b.TeamID
+		}
+
+		return strings.Compare(a.Field, b.Field)
+	})
+
+	return diffs
+}
+
+// CompetitionStandings recomputes the standings of a competition from its matches, applying the penalty and
+// seeded points of the official tables unless adjustments are already configured. The official tables are
+// returned alongside so that they can be compared using DiffStandings. Golden caps are not included as the match
+// list does not carry per map results.
+func (client *Client) CompetitionStandings(ctx context.Context, httpClient HTTPExecutor, competitionID int, config StandingsConfig) ([]StandingsRow, map[string]CompetitionTable, error) {
+	matches, errMatches := client.CompetitionMatches(ctx, httpClient, competitionID, BaseOpts{Recursive: true})
+	if errMatches != nil {
+		return nil, nil, errMatches
+	}
+
+	tables, errTables := client.CompetitionTables(ctx, httpClient, competitionID)
+	if errTables != nil {
+		return nil, nil, errTables
+	}
+
+	if config.Adjustments == nil {
+		config.Adjustments = AdjustmentsFromTables(tables)
+	}
+
+	standingsMatches := make([]StandingsMatch, len(matches))
+	for idx, match := range matches {
+		standingsMatches[idx] = StandingsMatchFromCompetitionMatch(match)
+	}
+
+	return ComputeStandings(standingsMatches, config), tables, nil
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/leighmacdonald/etf2l"
+	"github.com/pkg/errors"
+)
+
+// ResourceRecruitment is the sync_state entry recording when the recruitment snapshot was taken.
+const ResourceRecruitment = "recruitment"
+
+// LoadRecruitment implements etf2l.RecruitmentStateStore.
+func (s *Store) LoadRecruitment(ctx context.Context) (etf2l.RecruitmentSnapshot, error) {
+	var snapshot etf2l.RecruitmentSnapshot
+
+	taken, errCursor := s.Cursor(ctx, ResourceRecruitment)
+	if errCursor != nil {
+		return snapshot, errCursor
+	}
+
+	if taken == 0 {
+		return snapshot, nil
+	}
+
+	snapshot.Taken = time.Unix(int64(taken), 0)
+	snapshot.Players = map[int]etf2l.RecruitmentPostState{}
+	snapshot.Teams = map[int]etf2l.RecruitmentPostState{}
+
+	rows, errQuery := s.db.QueryContext(ctx, "SELECT kind, id, name, comment_count, last_comment FROM recruitment_posts")
+	if errQuery != nil {
+		return snapshot, errors.Wrap(errQuery, "Failed to query recruitment posts")
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var (
+			kind   string
+			postID int
+			state  etf2l.RecruitmentPostState
+		)
+
+		if err := rows.Scan(&kind, &postID, &state.Name, &state.Comments.Count, &state.Comments.Last); err != nil {
+			return snapshot, errors.Wrap(err, "Failed to scan recruitment post")
+		}
+
+		if etf2l.RecruitmentKind(kind) == etf2l.RecruitmentTeams {
+			snapshot.Teams[postID] = state
+		} else {
+			snapshot.Players[postID] = state
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return snapshot, errors.Wrap(err, "Failed to read recruitment posts")
+	}
+
+	return snapshot, nil
+}
+
+// SaveRecruitment implements etf2l.RecruitmentStateStore, replacing the stored snapshot.
+func (s *Store) SaveRecruitment(ctx context.Context, snapshot etf2l.RecruitmentSnapshot) error {
+	tx, errTx := s.db.BeginTx(ctx, nil)
+	if errTx != nil {
+		return errors.Wrap(errTx, "Failed to start transaction")
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM recruitment_posts"); err != nil {
+		return errors.Wrap(err, "Failed to clear recruitment posts")
+	}
+
+	for kind, posts := range map[etf2l.RecruitmentKind]map[int]etf2l.RecruitmentPostState{
+		etf2l.RecruitmentPlayers: snapshot.Players,
+		etf2l.RecruitmentTeams:   snapshot.Teams,
+	} {
+		for postID, state := range posts {
+			if _, err := tx.ExecContext(ctx,
+				"INSERT INTO recruitment_posts (kind, id, name, comment_count, last_comment) VALUES (?, ?, ?, ?, ?)",
+				string(kind), postID, state.Name, state.Comments.Count, state.Comments.Last); err != nil {
+				return errors.Wrap(err, "Failed to insert recruitment post")
+			}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO sync_state (resource, cursor, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (resource) DO UPDATE SET cursor = excluded.cursor, updated_at = excluded.updated_at`,
+		ResourceRecruitment, snapshot.Taken.Unix(), time.Now().Unix()); err != nil {
+		return errors.Wrap(err, "Failed to write cursor")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "Failed to commit transaction")
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+CREATE TABLE IF NOT EXISTS players
+(
+    id         INTEGER PRIMARY KEY,
+    name       TEXT    NOT NULL,
+    country    TEXT    NOT NULL,
+    steam_id64 INTEGER NOT NULL,
+    title      TEXT    NOT NULL,
+    registered INTEGER NOT NULL,
+    classes    TEXT    NOT NULL,
+    synced_at  INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS players_steam_id64 ON players (steam_id64);
+
+CREATE TABLE IF NOT EXISTS teams
+(
+    id        INTEGER PRIMARY KEY,
+    name      TEXT    NOT NULL,
+    tag       TEXT    NOT NULL,
+    country   TEXT    NOT NULL,
+    homepage  TEXT    NOT NULL,
+    server    TEXT    NOT NULL,
+    synced_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS team_players
+(
+    team_id   INTEGER NOT NULL,
+    player_id INTEGER NOT NULL,
+    name      TEXT    NOT NULL,
+    role      TEXT    NOT NULL,
+    PRIMARY KEY (team_id, player_id)
+);
+
+CREATE TABLE IF NOT EXISTS transfers
+(
+    player_id   INTEGER NOT NULL,
+    player_name TEXT    NOT NULL,
+    team_id     INTEGER NOT NULL,
+    team_name   TEXT    NOT NULL,
+    team_type   TEXT    NOT NULL,
+    type        TEXT    NOT NULL,
+    by_id       INTEGER NOT NULL,
+    time        INTEGER NOT NULL,
+    PRIMARY KEY (player_id, team_id, time, type)
+);
+
+CREATE INDEX IF NOT EXISTS transfers_team_id ON transfers (team_id, time);
+
+CREATE TABLE IF NOT EXISTS competitions
+(
+    id          INTEGER PRIMARY KEY,
+    name        TEXT    NOT NULL,
+    category    TEXT    NOT NULL,
+    type        TEXT    NOT NULL,
+    description TEXT    NOT NULL,
+    archived    INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS matches
+(
+    id               INTEGER PRIMARY KEY,
+    competition_id   INTEGER NOT NULL,
+    competition_name TEXT    NOT NULL,
+    division_id      INTEGER NOT NULL,
+    division_name    TEXT    NOT NULL,
+    tier             INTEGER NOT NULL,
+    clan1_id         INTEGER NOT NULL,
+    clan1_name       TEXT    NOT NULL,
+    clan2_id         INTEGER NOT NULL,
+    clan2_name       TEXT    NOT NULL,
+    r1               INTEGER NOT NULL,
+    r2               INTEGER NOT NULL,
+    round            TEXT    NOT NULL,
+    week             INTEGER NOT NULL,
+    time             INTEGER NOT NULL,
+    submitted        INTEGER NOT NULL,
+    defaultwin       INTEGER NOT NULL,
+    maps             TEXT    NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS matches_competition_id ON matches (competition_id);
+CREATE INDEX IF NOT EXISTS matches_clan1_id ON matches (clan1_id);
+CREATE INDEX IF NOT EXISTS matches_clan2_id ON matches (clan2_id);
+CREATE INDEX IF NOT EXISTS matches_time ON matches (time);
+
+CREATE TABLE IF NOT EXISTS results
+(
+    player_id      INTEGER NOT NULL,
+    competition_id INTEGER NOT NULL,
+    division_name  TEXT    NOT NULL,
+    clan1_id       INTEGER NOT NULL,
+    clan2_id       INTEGER NOT NULL,
+    r1             INTEGER NOT NULL,
+    r2             INTEGER NOT NULL,
+    result         INTEGER NOT NULL,
+    merced         INTEGER NOT NULL,
+    defaultwin     INTEGER NOT NULL,
+    round          TEXT    NOT NULL,
+    week           INTEGER NOT NULL,
+    time           INTEGER NOT NULL,
+    maps           TEXT    NOT NULL,
+    PRIMARY KEY (player_id, time, clan1_id, clan2_id)
+);
+
+CREATE TABLE IF NOT EXISTS bans
+(
+    steam_id64 INTEGER NOT NULL,
+    name       TEXT    NOT NULL,
+    start      INTEGER NOT NULL,
+    end        INTEGER NOT NULL,
+    expired    INTEGER NOT NULL,
+    reason     TEXT    NOT NULL,
+    PRIMARY KEY (steam_id64, start)
+);
+
+CREATE TABLE IF NOT EXISTS demos
+(
+    id           INTEGER PRIMARY KEY,
+    match_id     INTEGER NOT NULL,
+    time         INTEGER NOT NULL,
+    download_url TEXT    NOT NULL,
+    stv          INTEGER NOT NULL,
+    first_person INTEGER NOT NULL,
+    owner_id     INTEGER NOT NULL,
+    owner_name   TEXT    NOT NULL,
+    pruned       INTEGER NOT NULL,
+    file         TEXT    NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS demos_match_id ON demos (match_id);
+
+CREATE TABLE IF NOT EXISTS sync_state
+(
+    resource   TEXT PRIMARY KEY,
+    cursor     INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS recruitment_posts
+(
+    kind          TEXT    NOT NULL,
+    id            INTEGER NOT NULL,
+    name          TEXT    NOT NULL,
+    comment_count INTEGER NOT NULL,
+    last_comment  INTEGER NOT NULL,
+    PRIMARY KEY (kind, id)
+);
@@ -0,0 +1,399 @@
+// Package store mirrors ETF2L data into a local SQLite database so it can be queried with SQL.
+package store
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/leighmacdonald/etf2l"
+	"github.com/pkg/errors"
+	_ "modernc.org/sqlite"
+)
+
+//go:embed schema.sql
+var schema string
+
+// Store is a SQLite mirror of the ETF2L api.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens, creating if required, the SQLite database at path and applies the schema.
+func Open(ctx context.Context, path string) (*Store, error) {
+	dsn := url.URL{
+		Scheme:   "file",
+		Path:     path,
+		OmitHost: true,
+		RawQuery: "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)",
+	}
+
+	db, errOpen := sql.Open("sqlite", dsn.String())
+	if errOpen != nil {
+		return nil, errors.Wrap(errOpen, "Failed to open database")
+	}
+
+	store, errNew := New(ctx, db)
+	if errNew != nil {
+		_ = db.Close()
+
+		return nil, errNew
+	}
+
+	return store, nil
+}
+
+// New creates a store using an existing database handle, applying the schema if it does not exist yet.
+func New(ctx context.Context, db *sql.DB) (*Store, error) {
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, errors.Wrap(err, "Failed to apply schema")
+	}
+
+	return &Store{db: db}, nil
+}
+
+// DB returns the underlying database handle for running queries.
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Cursor returns the sync cursor of a resource, 0 if it has never been synced.
+func (s *Store) Cursor(ctx context.Context, resource string) (int, error) {
+	var cursor int
+
+	err := s.db.QueryRowContext(ctx, "SELECT cursor FROM sync_state WHERE resource = ?", resource).Scan(&cursor)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+
+		return 0, errors.Wrap(err, "Failed to read cursor")
+	}
+
+	return cursor, nil
+}
+
+// SetCursor updates the sync cursor of a resource.
+func (s *Store) SetCursor(ctx context.Context, resource string, cursor int) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO sync_state (resource, cursor, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (resource) DO UPDATE SET cursor = excluded.cursor, updated_at = excluded.updated_at`,
+		resource, cursor, time.Now().Unix())
+	if err != nil {
+		return errors.Wrap(err, "Failed to write cursor")
+	}
+
+	return nil
+}
+
+// table describes how rows of a table are upserted.
+type table struct {
+	name    string
+	key     []string
+	columns []string
+}
+
+// upsert inserts or updates rows, returning the number of rows that were inserted or changed. Rows identical
+// to the stored copy are left untouched so they are not counted.
+func (s *Store) upsert(ctx context.Context, tbl table, rows [][]any) (int, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	tx, errTx := s.db.BeginTx(ctx, nil)
+	if errTx != nil {
+		return 0, errors.Wrap(errTx, "Failed to start transaction")
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	changed, errUpsert := upsertTx(ctx, tx, tbl, rows)
+	if errUpsert != nil {
+		return 0, errUpsert
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "Failed to commit transaction")
+	}
+
+	return changed, nil
+}
+
+// upsertTx performs an upsert within an existing transaction.
+func upsertTx(ctx context.Context, tx *sql.Tx, tbl table, rows [][]any) (int, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	var (
+		updates    []string
+		conditions []string
+	)
+
+	for _, column := range tbl.columns {
+		if slices.Contains(tbl.key, column) {
+			continue
+		}
+
+		updates = append(updates, fmt.Sprintf("%s = excluded.%s", column, column))
+		conditions = append(conditions, fmt.Sprintf("%s.%s IS NOT excluded.%s", tbl.name, column, column))
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s WHERE %s",
+		tbl.name,
+		strings.Join(tbl.columns, ", "),
+		strings.TrimSuffix(strings.Repeat("?, ", len(tbl.columns)), ", "),
+		strings.Join(tbl.key, ", "),
+		strings.Join(updates, ", "),
+		strings.Join(conditions, " OR "))
+
+	if len(updates) == 0 {
+		query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT DO NOTHING",
+			tbl.name,
+			strings.Join(tbl.columns, ", "),
+			strings.TrimSuffix(strings.Repeat("?, ", len(tbl.columns)), ", "))
+	}
+
+	stmt, errPrepare := tx.PrepareContext(ctx, query)
+	if errPrepare != nil {
+		return 0, errors.Wrapf(errPrepare, "Failed to prepare %s upsert", tbl.name)
+	}
+
+	defer func() { _ = stmt.Close() }()
+
+	var changed int
+
+	for _, row := range rows {
+		result, errExec := stmt.ExecContext(ctx, row...)
+		if errExec != nil {
+			return 0, errors.Wrapf(errExec, "Failed to upsert %s", tbl.name)
+		}
+
+		affected, errAffected := result.RowsAffected()
+		if errAffected != nil {
+			return 0, errors.Wrap(errAffected, "Failed to read affected rows")
+		}
+
+		changed += int(affected)
+	}
+
+	return changed, nil
+}
+
+func encodeList(values []string) string {
+	if values == nil {
+		values = []string{}
+	}
+
+	encoded, _ := json.Marshal(values)
+
+	return string(encoded)
+}
+
+var playersTable = table{
+	name:    "players",
+	key:     []string{"id"},
+	columns: []string{"id", "name", "country", "steam_id64", "title", "registered", "classes", "synced_at"},
+}
+
+// UpsertPlayer stores a player profile.
+func (s *Store) UpsertPlayer(ctx context.Context, player etf2l.Player) error {
+	_, err := s.upsert(ctx, playersTable, [][]any{{
+		player.ID, player.Name, player.Country, player.Steam.ID64.Int64(), player.Title, player.Registered,
+		encodeList(player.Classes), time.Now().Unix(),
+	}})
+
+	return err
+}
+
+var (
+	teamsTable = table{
+		name:    "teams",
+		key:     []string{"id"},
+		columns: []string{"id", "name", "tag", "country", "homepage", "server", "synced_at"},
+	}
+	teamPlayersTable = table{
+		name:    "team_players",
+		key:     []string{"team_id", "player_id"},
+		columns: []string{"team_id", "player_id", "name", "role"},
+	}
+)
+
+// UpsertTeam stores a team along with its current roster, replacing the previously stored roster.
+func (s *Store) UpsertTeam(ctx context.Context, team etf2l.Team) error {
+	tx, errTx := s.db.BeginTx(ctx, nil)
+	if errTx != nil {
+		return errors.Wrap(errTx, "Failed to start transaction")
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := upsertTx(ctx, tx, teamsTable, [][]any{{
+		team.ID, team.Name, team.Tag, team.Country, team.Homepage, team.Server, time.Now().Unix(),
+	}}); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM team_players WHERE team_id = ?", team.ID); err != nil {
+		return errors.Wrap(err, "Failed to clear roster")
+	}
+
+	rows := make([][]any, len(team.Players))
+	for idx, player := range team.Players {
+		rows[idx] = []any{team.ID, player.ID, player.Name, player.Role}
+	}
+
+	if _, err := upsertTx(ctx, tx, teamPlayersTable, rows); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "Failed to commit transaction")
+	}
+
+	return nil
+}
+
+var transfersTable = table{
+	name:    "transfers",
+	key:     []string{"player_id", "team_id", "time", "type"},
+	columns: []string{"player_id", "player_name", "team_id", "team_name", "team_type", "type", "by_id", "time"},
+}
+
+// UpsertPlayerTransfers stores the transfers of a player.
+func (s *Store) UpsertPlayerTransfers(ctx context.Context, player etf2l.Player, transfers []etf2l.PlayerTransfer) (int, error) {
+	rows := make([][]any, len(transfers))
+	for idx, transfer := range transfers {
+		rows[idx] = []any{
+			player.ID, player.Name, transfer.Team.ID, transfer.Team.Name, transfer.Team.Type, transfer.Type,
+			transfer.By.ID, transfer.Time,
+		}
+	}
+
+	return s.upsert(ctx, transfersTable, rows)
+}
+
+// UpsertTeamTransfers stores the transfers of a team.
+func (s *Store) UpsertTeamTransfers(ctx context.Context, transfers []etf2l.TeamTransfer) (int, error) {
+	rows := make([][]any, len(transfers))
+	for idx, transfer := range transfers {
+		rows[idx] = []any{
+			transfer.Who.ID, transfer.Who.Name, transfer.Team.ID, transfer.Team.Name, transfer.Team.Type, transfer.Type,
+			transfer.By.ID, transfer.Time,
+		}
+	}
+
+	return s.upsert(ctx, transfersTable, rows)
+}
+
+var competitionsTable = table{
+	name:    "competitions",
+	key:     []string{"id"},
+	columns: []string{"id", "name", "category", "type", "description", "archived"},
+}
+
+// UpsertCompetitions stores competitions.
+func (s *Store) UpsertCompetitions(ctx context.Context, competitions []etf2l.Competition) (int, error) {
+	rows := make([][]any, len(competitions))
+	for idx, competition := range competitions {
+		rows[idx] = []any{
+			competition.ID, competition.Name, competition.Category, competition.Type, competition.Description,
+			competition.Archived,
+		}
+	}
+
+	return s.upsert(ctx, competitionsTable, rows)
+}
+
+var matchesTable = table{
+	name: "matches",
+	key:  []string{"id"},
+	columns: []string{
+		"id", "competition_id", "competition_name", "division_id", "division_name", "tier", "clan1_id",
+		"clan1_name", "clan2_id", "clan2_name", "r1", "r2", "round", "week", "time", "submitted", "defaultwin", "maps",
+	},
+}
+
+// UpsertMatches stores matches.
+func (s *Store) UpsertMatches(ctx context.Context, matches []etf2l.Match) (int, error) {
+	rows := make([][]any, len(matches))
+	for idx, match := range matches {
+		rows[idx] = []any{
+			match.ID, match.Competition.ID, match.Competition.Name, match.Division.ID, match.Division.Name,
+			match.Division.Tier, match.Clan1.ID, match.Clan1.Name, match.Clan2.ID, match.Clan2.Name, match.R1, match.R2,
+			match.Round, match.Week, match.Time, match.Submitted, match.Defaultwin, encodeList(match.Maps),
+		}
+	}
+
+	return s.upsert(ctx, matchesTable, rows)
+}
+
+var resultsTable = table{
+	name: "results",
+	key:  []string{"player_id", "time", "clan1_id", "clan2_id"},
+	columns: []string{
+		"player_id", "competition_id", "division_name", "clan1_id", "clan2_id", "r1", "r2", "result", "merced",
+		"defaultwin", "round", "week", "time", "maps",
+	},
+}
+
+// UpsertPlayerResults stores the results of a player.
+func (s *Store) UpsertPlayerResults(ctx context.Context, playerID int, results []etf2l.PlayerResult) (int, error) {
+	rows := make([][]any, len(results))
+	for idx, result := range results {
+		rows[idx] = []any{
+			playerID, result.Competition.ID, result.Division.Name, result.Clan1.ID, result.Clan2.ID, result.R1,
+			result.R2, result.Result, result.Merced, result.Defaultwin, result.Round, result.Week, result.Time,
+			encodeList(result.Maps),
+		}
+	}
+
+	return s.upsert(ctx, resultsTable, rows)
+}
+
+var bansTable = table{
+	name:    "bans",
+	key:     []string{"steam_id64", "start"},
+	columns: []string{"steam_id64", "name", "start", "end", "expired", "reason"},
+}
+
+// UpsertBans stores bans.
+func (s *Store) UpsertBans(ctx context.Context, bans []etf2l.Ban) (int, error) {
+	rows := make([][]any, len(bans))
+	for idx, ban := range bans {
+		rows[idx] = []any{ban.Steamid64.Int64(), ban.Name, ban.Start, ban.End, ban.Expired, ban.Reason}
+	}
+
+	return s.upsert(ctx, bansTable, rows)
+}
+
+var demosTable = table{
+	name: "demos",
+	key:  []string{"id"},
+	columns: []string{
+		"id", "match_id", "time", "download_url", "stv", "first_person", "owner_id", "owner_name", "pruned", "file",
+	},
+}
+
+// UpsertDemos stores demos.
+func (s *Store) UpsertDemos(ctx context.Context, demos []etf2l.Demo) (int, error) {
+	rows := make([][]any, len(demos))
+	for idx, demo := range demos {
+		rows[idx] = []any{
+			demo.ID, demo.Match, demo.Time, demo.DownloadURL, demo.Stv, demo.FirstPerson, demo.Owner, demo.OwnerName,
+			demo.Pruned, demo.File,
+		}
+	}
+
+	return s.upsert(ctx, demosTable, rows)
+}
@@ -0,0 +1,201 @@
+package store_test
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leighmacdonald/etf2l"
+	"github.com/leighmacdonald/etf2l/etf2ltest"
+	"github.com/leighmacdonald/etf2l/store"
+	"github.com/leighmacdonald/steamid/v4/steamid"
+	"github.com/stretchr/testify/require"
+)
+
+const day = 24 * 60 * 60
+
+func newMatch(matchID int, played int, submitted bool) etf2l.Match {
+	match := etf2l.Match{
+		ID:    matchID,
+		Clan1: etf2l.MatchClan{ID: 1, Name: "one"},
+		Clan2: etf2l.MatchClan{ID: 2, Name: "two"},
+		Time:  played,
+		Maps:  []string{"cp_process_final"},
+	}
+
+	if submitted {
+		match.Submitted = played + day
+		match.R1 = 3
+	}
+
+	return match
+}
+
+// queryRecorder records the raw query of every request.
+type queryRecorder struct {
+	sync.Mutex
+	queries []string
+}
+
+func (r *queryRecorder) middleware(next etf2l.HTTPExecutor) etf2l.HTTPExecutor {
+	return etf2l.HTTPExecutorFunc(func(req *http.Request) (*http.Response, error) {
+		r.Lock()
+		r.queries = append(r.queries, req.URL.RawQuery)
+		r.Unlock()
+
+		return next.Do(req)
+	})
+}
+
+func TestSyncer(t *testing.T) {
+	ctx := context.Background()
+
+	database, errOpen := store.Open(ctx, filepath.Join(t.TempDir(), "etf2l.db"))
+	require.NoError(t, errOpen)
+
+	defer func() { _ = database.Close() }()
+
+	bans := []etf2l.Ban{{Steamid64: steamid.New("76561198203516436"), Name: "cheater", Start: 1000, End: 2000, Reason: "VAC"}}
+	fixtures := etf2ltest.Fixtures{
+		Competitions: []etf2l.CompetitionDetails{{ID: 1, Name: "Season 1", Type: "6on6"}},
+		Matches:      []etf2l.Match{newMatch(1, 100*day, true), newMatch(2, 110*day, false)},
+		Demos:        []etf2l.Demo{{ID: 1, Match: 1, Time: 101 * day}},
+		Bans:         bans,
+	}
+
+	server := etf2ltest.NewServer(fixtures)
+	results, errSync := store.NewSyncer(database, server.NewClient(), server.Client()).Sync(ctx)
+	server.Close()
+
+	require.NoError(t, errSync)
+	require.Equal(t, []store.SyncResult{
+		{Resource: store.ResourceCompetitions, Fetched: 1, Changed: 1},
+		{Resource: store.ResourceMatches, Fetched: 2, Changed: 2, Cursor: 100 * day},
+		{Resource: store.ResourceDemos, Fetched: 1, Changed: 1, Cursor: 101 * day},
+		{Resource: store.ResourceBans, Fetched: 1, Changed: 1, Cursor: 1000},
+	}, results)
+
+	// The scheduled match has been played and a new one was scheduled.
+	fixtures.Matches = []etf2l.Match{newMatch(1, 100*day, true), newMatch(2, 110*day, true), newMatch(3, 120*day, false)}
+	// A demo uploaded before the demo cursor is outside the incremental sync.
+	fixtures.Demos = append(fixtures.Demos, etf2l.Demo{ID: 2, Match: 1, Time: 50 * day})
+	server = etf2ltest.NewServer(fixtures)
+
+	defer server.Close()
+
+	var recorder queryRecorder
+
+	syncer := store.NewSyncer(database, server.NewClient(etf2l.WithMiddleware(recorder.middleware)), server.Client())
+	result, errMatches := syncer.SyncMatches(ctx)
+	require.NoError(t, errMatches)
+	require.Equal(t, store.SyncResult{Resource: store.ResourceMatches, Fetched: 3, Changed: 2, Cursor: 110 * day}, result)
+	require.Contains(t, recorder.queries[0], "from=7430400")
+
+	var r1, submitted int
+	require.NoError(t, database.DB().QueryRowContext(ctx, "SELECT r1, submitted FROM matches WHERE id = 2").Scan(&r1, &submitted))
+	require.Equal(t, 3, r1)
+	require.Equal(t, 111*day, submitted)
+
+	recorder.queries = nil
+
+	demos, errDemos := syncer.SyncDemos(ctx)
+	require.NoError(t, errDemos)
+	require.Equal(t, store.SyncResult{Resource: store.ResourceDemos, Fetched: 1, Changed: 0, Cursor: 101 * day}, demos)
+	require.Contains(t, recorder.queries[0], "from=7516800")
+
+	unchanged, errBans := syncer.SyncBans(ctx)
+	require.NoError(t, errBans)
+	require.Equal(t, 0, unchanged.Changed)
+
+	cursor, errCursor := database.Cursor(ctx, store.ResourceMatches)
+	require.NoError(t, errCursor)
+	require.Equal(t, 110*day, cursor)
+}
+
+func TestUpsertTeam(t *testing.T) {
+	ctx := context.Background()
+
+	// Characters with a meaning within the dsn are part of the file name.
+	path := filepath.Join(t.TempDir(), "etf2l?mode=ro#100%.db")
+
+	database, errOpen := store.Open(ctx, path)
+	require.NoError(t, errOpen)
+
+	defer func() { _ = database.Close() }()
+
+	require.FileExists(t, path)
+
+	var journal string
+	require.NoError(t, database.DB().QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&journal))
+	require.Equal(t, "wal", journal)
+
+	roster := func() []int {
+		rows, err := database.DB().QueryContext(ctx, "SELECT player_id FROM team_players WHERE team_id = 2 ORDER BY player_id")
+		require.NoError(t, err)
+
+		defer func() { _ = rows.Close() }()
+
+		var ids []int
+
+		for rows.Next() {
+			var playerID int
+			require.NoError(t, rows.Scan(&playerID))
+
+			ids = append(ids, playerID)
+		}
+
+		require.NoError(t, rows.Err())
+
+		return ids
+	}
+
+	team := etf2l.Team{ID: 2, Name: "Froyotech", Players: []etf2l.TeamPlayer{{ID: 1, Name: "one", Role: "Leader"}, {ID: 2, Name: "two"}}}
+	require.NoError(t, database.UpsertTeam(ctx, team))
+	require.Equal(t, []int{1, 2}, roster())
+
+	_, errTrigger := database.DB().ExecContext(ctx, `
+		CREATE TRIGGER reject_player BEFORE INSERT ON team_players WHEN NEW.player_id = 3
+		BEGIN SELECT RAISE(ABORT, 'rejected'); END`)
+	require.NoError(t, errTrigger)
+
+	// A failure while storing the new roster keeps the previous one.
+	team.Name = "renamed"
+	team.Players = []etf2l.TeamPlayer{{ID: 3, Name: "three"}}
+	require.Error(t, database.UpsertTeam(ctx, team))
+	require.Equal(t, []int{1, 2}, roster())
+
+	var name string
+	require.NoError(t, database.DB().QueryRowContext(ctx, "SELECT name FROM teams WHERE id = 2").Scan(&name))
+	require.Equal(t, "Froyotech", name)
+}
+
+func TestRecruitmentState(t *testing.T) {
+	ctx := context.Background()
+
+	database, errOpen := store.Open(ctx, filepath.Join(t.TempDir(), "etf2l.db"))
+	require.NoError(t, errOpen)
+
+	defer func() { _ = database.Close() }()
+
+	var stateStore etf2l.RecruitmentStateStore = database
+
+	empty, errEmpty := stateStore.LoadRecruitment(ctx)
+	require.NoError(t, errEmpty)
+	require.True(t, empty.Taken.IsZero())
+
+	snapshot := etf2l.RecruitmentSnapshot{
+		Taken:   time.Unix(1700000000, 0),
+		Players: map[int]etf2l.RecruitmentPostState{9000: {Name: "b4nny", Comments: etf2l.RecruitmentComments{Count: 2, Last: 1690000000}}},
+		Teams:   map[int]etf2l.RecruitmentPostState{9000: {Name: "Froyotech"}},
+	}
+
+	require.NoError(t, stateStore.SaveRecruitment(ctx, snapshot))
+	require.NoError(t, stateStore.SaveRecruitment(ctx, snapshot))
+
+	loaded, errLoad := stateStore.LoadRecruitment(ctx)
+	require.NoError(t, errLoad)
+	require.Equal(t, snapshot, loaded)
+}
@@ -0,0 +1,250 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/leighmacdonald/etf2l"
+)
+
+const (
+	ResourceCompetitions = "competitions"
+	ResourceMatches      = "matches"
+	ResourceDemos        = "demos"
+	ResourceBans         = "bans"
+)
+
+// DefaultOverlap is how far before the previous cursor incremental syncs start, results are often submitted days
+// after a match was played.
+const DefaultOverlap = 14 * 24 * time.Hour
+
+// SyncResult summarises a single sync run of a resource.
+type SyncResult struct {
+	Resource string
+	// Fetched is the number of records returned by the api.
+	Fetched int
+	// Changed is the number of records which were new or differed from the stored copy.
+	Changed int
+	Cursor  int
+}
+
+// Syncer fills a Store through the api client. Matches and demos are synced incrementally, only fetching records
+// newer than the cursor stored by the previous run.
+type Syncer struct {
+	store      *Store
+	client     *etf2l.Client
+	httpClient etf2l.HTTPExecutor
+	// Overlap is subtracted from the stored cursor when syncing incrementally, defaults to DefaultOverlap.
+	Overlap time.Duration
+}
+
+func NewSyncer(store *Store, client *etf2l.Client, httpClient etf2l.HTTPExecutor) *Syncer {
+	return &Syncer{store: store, client: client, httpClient: httpClient, Overlap: DefaultOverlap}
+}
+
+// Sync syncs all resources which can be listed: competitions, matches, demos and bans. Players and teams are
+// synced on demand with SyncPlayer and SyncTeam.
+func (s *Syncer) Sync(ctx context.Context) ([]SyncResult, error) {
+	var results []SyncResult
+
+	for _, syncFn := range []func(context.Context) (SyncResult, error){
+		s.SyncCompetitions, s.SyncMatches, s.SyncDemos, s.SyncBans,
+	} {
+		result, err := syncFn(ctx)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// from returns the timestamp an incremental sync of the resource starts from, 0 for a full sync.
+func (s *Syncer) from(ctx context.Context, resource string) (int, int, error) {
+	cursor, err := s.store.Cursor(ctx, resource)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if cursor == 0 {
+		return 0, 0, nil
+	}
+
+	return cursor, max(cursor-int(s.Overlap.Seconds()), 1), nil
+}
+
+// SyncCompetitions syncs the full competition list.
+func (s *Syncer) SyncCompetitions(ctx context.Context) (SyncResult, error) {
+	competitions, errCompetitions := s.client.CompetitionList(ctx, s.httpClient, etf2l.CompetitionOpts{
+		Recursive: etf2l.BaseOpts{Recursive: true},
+	})
+	if errCompetitions != nil {
+		return SyncResult{}, errCompetitions
+	}
+
+	changed, errUpsert := s.store.UpsertCompetitions(ctx, competitions)
+	if errUpsert != nil {
+		return SyncResult{}, errUpsert
+	}
+
+	return SyncResult{Resource: ResourceCompetitions, Fetched: len(competitions), Changed: changed}, nil
+}
+
+// SyncMatches syncs matches played since the last sync. The cursor is the time of the latest submitted match,
+// scheduled matches within the overlap are fetched again until their results are submitted.
+func (s *Syncer) SyncMatches(ctx context.Context) (SyncResult, error) {
+	cursor, from, errFrom := s.from(ctx, ResourceMatches)
+	if errFrom != nil {
+		return SyncResult{}, errFrom
+	}
+
+	matches, _, errMatches := s.client.Matches(ctx, s.httpClient, etf2l.MatchesOpts{
+		BaseOpts: etf2l.BaseOpts{Recursive: true},
+		From:     from,
+	})
+	if errMatches != nil {
+		return SyncResult{}, errMatches
+	}
+
+	changed, errUpsert := s.store.UpsertMatches(ctx, matches)
+	if errUpsert != nil {
+		return SyncResult{}, errUpsert
+	}
+
+	for _, match := range matches {
+		if match.Submitted != 0 {
+			cursor = max(cursor, match.Time)
+		}
+	}
+
+	if err := s.store.SetCursor(ctx, ResourceMatches, cursor); err != nil {
+		return SyncResult{}, err
+	}
+
+	return SyncResult{Resource: ResourceMatches, Fetched: len(matches), Changed: changed, Cursor: cursor}, nil
+}
+
+// SyncDemos syncs demos uploaded since the last sync.
+func (s *Syncer) SyncDemos(ctx context.Context) (SyncResult, error) {
+	cursor, from, errFrom := s.from(ctx, ResourceDemos)
+	if errFrom != nil {
+		return SyncResult{}, errFrom
+	}
+
+	demos, errDemos := s.client.Demos(ctx, s.httpClient, etf2l.DemoOpts{
+		Recursive: etf2l.BaseOpts{Recursive: true},
+		From:      from,
+	})
+	if errDemos != nil {
+		return SyncResult{}, errDemos
+	}
+
+	changed, errUpsert := s.store.UpsertDemos(ctx, demos)
+	if errUpsert != nil {
+		return SyncResult{}, errUpsert
+	}
+
+	for _, demo := range demos {
+		cursor = max(cursor, demo.Time)
+	}
+
+	if err := s.store.SetCursor(ctx, ResourceDemos, cursor); err != nil {
+		return SyncResult{}, err
+	}
+
+	return SyncResult{Resource: ResourceDemos, Fetched: len(demos), Changed: changed, Cursor: cursor}, nil
+}
+
+// SyncBans syncs the ban list. The api has no time filter for bans and existing bans can expire, so the full
+// list is fetched on every run, only changed rows are written.
+func (s *Syncer) SyncBans(ctx context.Context) (SyncResult, error) {
+	bans, errBans := s.client.Bans(ctx, s.httpClient, etf2l.BanOpts{Recursive: etf2l.BaseOpts{Recursive: true}})
+	if errBans != nil {
+		return SyncResult{}, errBans
+	}
+
+	changed, errUpsert := s.store.UpsertBans(ctx, bans)
+	if errUpsert != nil {
+		return SyncResult{}, errUpsert
+	}
+
+	var cursor int
+	for _, ban := range bans {
+		cursor = max(cursor, ban.Start)
+	}
+
+	if err := s.store.SetCursor(ctx, ResourceBans, cursor); err != nil {
+		return SyncResult{}, err
+	}
+
+	return SyncResult{Resource: ResourceBans, Fetched: len(bans), Changed: changed, Cursor: cursor}, nil
+}
+
+// SyncPlayer syncs the profile, results and transfers of a player.
+func (s *Syncer) SyncPlayer(ctx context.Context, playerID int) (SyncResult, error) {
+	player, errPlayer := s.client.Player(ctx, s.httpClient, strconv.Itoa(playerID))
+	if errPlayer != nil {
+		return SyncResult{}, errPlayer
+	}
+
+	results, errResults := s.client.PlayerResults(ctx, s.httpClient, strconv.Itoa(playerID), etf2l.BaseOpts{Recursive: true})
+	if errResults != nil {
+		return SyncResult{}, errResults
+	}
+
+	transfers, errTransfers := s.client.PlayerTransfers(ctx, s.httpClient, playerID, etf2l.BaseOpts{Recursive: true})
+	if errTransfers != nil {
+		return SyncResult{}, errTransfers
+	}
+
+	if err := s.store.UpsertPlayer(ctx, *player); err != nil {
+		return SyncResult{}, err
+	}
+
+	changedResults, errUpsertResults := s.store.UpsertPlayerResults(ctx, player.ID, results)
+	if errUpsertResults != nil {
+		return SyncResult{}, errUpsertResults
+	}
+
+	changedTransfers, errUpsertTransfers := s.store.UpsertPlayerTransfers(ctx, *player, transfers)
+	if errUpsertTransfers != nil {
+		return SyncResult{}, errUpsertTransfers
+	}
+
+	return SyncResult{
+		Resource: "player/" + strconv.Itoa(playerID),
+		Fetched:  1 + len(results) + len(transfers),
+		Changed:  changedResults + changedTransfers,
+	}, nil
+}
+
+// SyncTeam syncs the profile, roster and transfers of a team.
+func (s *Syncer) SyncTeam(ctx context.Context, teamID int) (SyncResult, error) {
+	team, errTeam := s.client.Team(ctx, s.httpClient, teamID)
+	if errTeam != nil {
+		return SyncResult{}, errTeam
+	}
+
+	transfers, errTransfers := s.client.TeamTransfers(ctx, s.httpClient, teamID, etf2l.BaseOpts{Recursive: true})
+	if errTransfers != nil {
+		return SyncResult{}, errTransfers
+	}
+
+	if err := s.store.UpsertTeam(ctx, *team); err != nil {
+		return SyncResult{}, err
+	}
+
+	changed, errUpsert := s.store.UpsertTeamTransfers(ctx, transfers)
+	if errUpsert != nil {
+		return SyncResult{}, errUpsert
+	}
+
+	return SyncResult{
+		Resource: "team/" + strconv.Itoa(teamID),
+		Fetched:  1 + len(transfers),
+		Changed:  changed,
+	}, nil
+}